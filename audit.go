@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ruoli non legati a una sessione web
+const (
+	ACTOR_CLI    = "cli"
+	ACTOR_SYSTEM = "system"
+)
+
+// requestIdMiddleware assegna a ogni richiesta un ID (riusando X-Request-ID se
+// inviato dal proxy) che finisce nei log di audit e nella risposta.
+func requestIdMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestId := ctx.GetHeader("X-Request-ID")
+		if requestId == "" || len(requestId) > 64 {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			requestId = hex.EncodeToString(buf)
+		}
+		ctx.Set("request_id", requestId)
+		ctx.Writer.Header().Set("X-Request-ID", requestId)
+		ctx.Next()
+	}
+}
+
+// newAuditEntry prepara una voce di audit con attore, IP e request ID della richiesta
+func newAuditEntry(ctx *gin.Context, action, targetType string, targetId int) AuditEntry {
+	entry := AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		ActorRole:  ctx.GetString("role"),
+	}
+	// L'account statico users_only ha ID -1, che non esiste in moderators
+	if moderatorId := ctx.GetInt("moderator_id"); moderatorId > 0 {
+		entry.ActorID = &moderatorId
+	}
+	ip := ctx.ClientIP()
+	entry.IP = &ip
+	if requestId := ctx.GetString("request_id"); requestId != "" {
+		entry.RequestID = &requestId
+	}
+	return entry
+}
+
+// cliAuditEntry prepara una voce di audit per le azioni eseguite da riga di comando
+func cliAuditEntry(action, targetType string, targetId int) AuditEntry {
+	return AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		ActorRole:  ACTOR_CLI,
+	}
+}
+
+// runAudited esegue mutate in una transazione e registra la voce di audit
+// nello stesso commit: o entrambe le scritture vanno a buon fine o nessuna.
+// mutate restituisce i valori prima/dopo da salvare nella voce.
+// Se db è già una transazione il blocco diventa un savepoint.
+func runAudited(db DBTX, entry AuditEntry, mutate func(tx pgx.Tx) (any, any, error)) error {
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// I trigger degli eventi in tempo reale leggono chi ha agito
+	if entry.ActorID != nil {
+		if err := SetEventActor(tx, *entry.ActorID); err != nil {
+			return err
+		}
+	}
+
+	before, after, err := mutate(tx)
+	if err != nil {
+		return err
+	}
+
+	entry.Before, entry.After = before, after
+	if err := InsertAuditEntry(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+// auditedStatusChange applica un cambio di stato a un contenuto registrando
+// lo stato precedente e quello risultante (con motivo e nota se rifiutato).
+// La decisione chiude l'eventuale riserva sull'elemento; i rifiuti ne
+// registrano l'impronta per riconoscere i reinvii.
+func auditedStatusChange(db DBTX, entry AuditEntry, kind *ContentKind, id int, apply func(tx pgx.Tx) error) error {
+	return runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		before, err := QueryContentStatus(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkClaim(tx, kind, id, entry.ActorID); err != nil {
+			return nil, nil, err
+		}
+		if err := apply(tx); err != nil {
+			return nil, nil, err
+		}
+		if err := DeleteClaim(tx, kind, id); err != nil {
+			return nil, nil, err
+		}
+		after, err := QueryContentStatus(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		afterValue := map[string]any{"status": after}
+		if after == "rejected" {
+			reason, note, err := QueryContentRejection(tx, kind, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			afterValue["reason"], afterValue["note"] = reason, note
+			if err := recordRejection(tx, entry, kind, id); err != nil {
+				return nil, nil, err
+			}
+		} else if before == "rejected" {
+			// Il rifiuto è stato annullato: i reinvii non vanno più segnalati
+			if err := DeleteRejectedFingerprint(tx, kind, id); err != nil {
+				return nil, nil, err
+			}
+		}
+		return map[string]any{"status": before}, afterValue, nil
+	})
+}
+
+// auditedDelete sposta un contenuto nel cestino salvandone una copia nella voce di audit
+func auditedDelete(db DBTX, entry AuditEntry, kind *ContentKind, id int) (DeleteResult, error) {
+	var result DeleteResult
+	err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		before, err := QueryContentSnapshot(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkClaim(tx, kind, id, entry.ActorID); err != nil {
+			return nil, nil, err
+		}
+		result, err = TrashContent(tx, kind, id, entry.ActorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := DeleteClaim(tx, kind, id); err != nil {
+			return nil, nil, err
+		}
+		return before, result, nil
+	})
+	return result, err
+}
+
+// auditedRestore riporta un contenuto dal cestino
+func auditedRestore(db DBTX, entry AuditEntry, kind *ContentKind, id int) (RestoreResult, error) {
+	var result RestoreResult
+	err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		var err error
+		result, err = RestoreContent(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := QueryContentSnapshot(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		return map[string]any{"trashed": true}, after, nil
+	})
+	return result, err
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
 )
 
 // Credenziali statiche per accesso limitato (solo gestione utenti/rappresentanti)
@@ -68,6 +72,55 @@ func validateToken(tokenString string) (int, string, error) {
 	return 0, "", fmt.Errorf("invalid token")
 }
 
+// Per quanto resta valido lo stato letto di un moderatore: disabilitazioni e
+// cambi di is_admin fatti dalla CLI valgono entro questo intervallo
+const MODERATOR_ACCESS_TTL = 30 * time.Second
+
+type moderatorAccess struct {
+	Moderator Moderator
+	Disabled  bool
+	Missing   bool
+	CheckedAt time.Time
+}
+
+var moderatorAccessCache = struct {
+	sync.Mutex
+	entries map[int]moderatorAccess
+}{entries: map[int]moderatorAccess{}}
+
+// lookupModeratorAccess restituisce lo stato del moderatore, riletto dal
+// database al massimo ogni MODERATOR_ACCESS_TTL per non aprire una seconda
+// connessione a ogni richiesta
+func lookupModeratorAccess(moderatorId int) (moderatorAccess, error) {
+	moderatorAccessCache.Lock()
+	cached, ok := moderatorAccessCache.entries[moderatorId]
+	moderatorAccessCache.Unlock()
+	if ok && time.Since(cached.CheckedAt) < MODERATOR_ACCESS_TTL {
+		return cached, nil
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		return moderatorAccess{}, err
+	}
+	defer db.Close(context.Background())
+
+	moderator, disabled, err := QueryModeratorAccess(db, moderatorId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return moderatorAccess{}, err
+	}
+	access := moderatorAccess{
+		Moderator: moderator,
+		Disabled:  disabled,
+		Missing:   err != nil,
+		CheckedAt: time.Now(),
+	}
+	moderatorAccessCache.Lock()
+	moderatorAccessCache.entries[moderatorId] = access
+	moderatorAccessCache.Unlock()
+	return access, nil
+}
+
 func authMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		if ctx.Request.Method == "OPTIONS" {
@@ -107,6 +160,33 @@ func authMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// L'account statico (-1) non è nel database; per gli altri si
+		// verifica che il moderatore esista e non sia disabilitato
+		if moderatorId > 0 {
+			access, err := lookupModeratorAccess(moderatorId)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+					Status: "error",
+					Error:  "query_error",
+					Msg:    "Error checking moderator account",
+				})
+				ctx.Abort()
+				return
+			}
+			moderator := access.Moderator
+			if access.Missing || access.Disabled {
+				ctx.JSON(http.StatusUnauthorized, ErrorResponse{
+					Status: "error",
+					Error:  "moderator_disabled",
+					Msg:    "Moderator account is disabled or no longer exists",
+				})
+				ctx.Abort()
+				return
+			}
+			ctx.Set("moderator", moderator)
+			ctx.Set("is_admin", moderator.IsAdmin)
+		}
+
 		ctx.Set("moderator_id", moderatorId)
 		ctx.Set("role", role)
 		ctx.Next()
@@ -200,7 +280,7 @@ func handleLogin(ctx *gin.Context) {
 	}
 
 	var moderator Moderator
-	if err := rows.Scan(&moderator.ID, &moderator.Username, &moderator.Name); err != nil {
+	if err := rows.Scan(&moderator.ID, &moderator.Username, &moderator.Name, &moderator.IsAdmin); err != nil {
 		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
 			Status: "error",
 			Error:  "scan_error",
@@ -227,9 +307,17 @@ func handleLogin(ctx *gin.Context) {
 	})
 }
 
+// handleVerify conferma il token e restituisce lo stato attuale del
+// moderatore, così la dashboard aggiorna i permessi cambiati dopo il login
 func handleVerify(ctx *gin.Context) {
-	ctx.JSON(http.StatusOK, SuccessResponse{
+	response := LoginResponse{
 		Status: "ok",
 		Msg:    "Token is valid",
-	})
+		Role:   ctx.GetString("role"),
+	}
+	if moderator, ok := ctx.Get("moderator"); ok {
+		m := moderator.(Moderator)
+		response.Moderator = &m
+	}
+	ctx.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	AUTO_HIDE_INTERVAL             = time.Minute
+	DEFAULT_AUTO_HIDE_WINDOW_HOURS = 24
+)
+
+// autoHideThreshold combina le soglie globali con quelle del tipo
+func autoHideThreshold(kind *ContentKind) AutoHideThreshold {
+	t := CONF.AUTO_HIDE
+	if override, ok := CONF.AUTO_HIDE_KINDS[kind.Name]; ok {
+		if override.REPORTS > 0 {
+			t.REPORTS = override.REPORTS
+		}
+		if override.DISTINCT_REPORTERS > 0 {
+			t.DISTINCT_REPORTERS = override.DISTINCT_REPORTERS
+		}
+		if override.WINDOW_HOURS > 0 {
+			t.WINDOW_HOURS = override.WINDOW_HOURS
+		}
+	}
+	if t.WINDOW_HOURS <= 0 {
+		t.WINDOW_HOURS = DEFAULT_AUTO_HIDE_WINDOW_HOURS
+	}
+	return t
+}
+
+// hideReportedContent riporta in attesa gli elementi approvati che hanno
+// superato le soglie di segnalazioni, con una voce di audit di sistema.
+func hideReportedContent(db *pgx.Conn) (int, error) {
+	hidden := 0
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		threshold := autoHideThreshold(kind)
+		if threshold.REPORTS <= 0 && threshold.DISTINCT_REPORTERS <= 0 {
+			continue
+		}
+
+		candidates, err := QueryAutoHideCandidates(db, kind, threshold)
+		if err != nil {
+			return hidden, err
+		}
+
+		for _, c := range candidates {
+			entry := AuditEntry{
+				Action:     "auto_hide",
+				TargetType: kind.Name,
+				TargetID:   c.ID,
+				ActorRole:  ACTOR_SYSTEM,
+			}
+			err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+				// Nel frattempo un moderatore potrebbe averlo già gestito
+				status, err := QueryContentStatus(tx, kind, c.ID)
+				if err != nil {
+					return nil, nil, err
+				}
+				if status != "approved" {
+					return nil, nil, errAlreadyHandled
+				}
+				if err := HideContent(tx, kind, c.ID); err != nil {
+					return nil, nil, err
+				}
+				return map[string]any{"status": status}, map[string]any{
+					"status":             "received",
+					"open_reports":       c.OpenReports,
+					"distinct_reporters": c.DistinctReporters,
+					"window_hours":       threshold.WINDOW_HOURS,
+				}, nil
+			})
+			if errors.Is(err, errAlreadyHandled) {
+				continue
+			}
+			if err != nil {
+				println("Auto-hide error ("+kind.Name+"):", err.Error())
+				continue
+			}
+			hidden++
+		}
+	}
+	return hidden, nil
+}
+
+// startAutoHider avvia in background il controllo periodico delle segnalazioni
+func startAutoHider() {
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("Auto-hide: cannot connect to database:", err.Error())
+			} else {
+				count, err := hideReportedContent(db)
+				if err != nil {
+					println("Auto-hide error:", err.Error())
+				} else if count > 0 {
+					println("Auto-hide: hid", count, "items")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(AUTO_HIDE_INTERVAL)
+		}
+	}()
+}
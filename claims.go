@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+)
+
+const (
+	DEFAULT_CLAIM_LEASE_MINUTES = 15
+	DEFAULT_CLAIM_BATCH         = 10
+	MAX_CLAIM_BATCH             = 50
+)
+
+// claimLeaseMinutes restituisce la durata di una riserva
+func claimLeaseMinutes() int {
+	if CONF.CLAIM_LEASE_MINUTES <= 0 {
+		return DEFAULT_CLAIM_LEASE_MINUTES
+	}
+	return CONF.CLAIM_LEASE_MINUTES
+}
+
+// claimConflictError: l'elemento è riservato da un altro moderatore
+type claimConflictError struct {
+	Claim ContentClaim
+}
+
+func (e *claimConflictError) Error() string {
+	return fmt.Sprintf("claimed by %s until %s", e.Claim.ModeratorName, e.Claim.ExpiresAt.Format("15:04"))
+}
+
+// checkClaim impedisce di decidere su un elemento riservato da un altro
+// moderatore. Sistema e CLI (actorId nil) non sono soggetti alle riserve.
+func checkClaim(db DBTX, kind *ContentKind, id int, actorId *int) error {
+	if actorId == nil {
+		return nil
+	}
+	claim, err := QueryActiveClaim(db, kind, id)
+	if err != nil {
+		return err
+	}
+	if claim != nil && claim.ModeratorID != *actorId {
+		return &claimConflictError{Claim: *claim}
+	}
+	return nil
+}
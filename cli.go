@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const CLI_USAGE = `Usage: connected_moderator_dashboard [command]
+
+Commands:
+  serve                                        start the web server (default)
+  moderator add [-password P] <username> <name> create a moderator
+  moderator passwd [-password P] <username>     change a moderator password
+  moderator disable|enable <username>          block or unblock a moderator login
+  moderator promote|demote <username>          grant or revoke admin rights (claim override)
+  moderator list                               list moderators
+  user set-role <user_id> <user|representative>
+  content approve|reject|delete|restore|dismiss-reports <post|spotted> <id>
+  content reject [-reason CODE] [-note TEXT] <post|spotted> <id>
+  trash purge                                  permanently remove expired trash items
+  stats [--json]                               print platform statistics
+  check-config                                 validate conf.yaml and test the database
+
+If -password is omitted the password is read from stdin.
+`
+
+// runCommand esegue un sottocomando e restituisce l'exit code
+func runCommand(cmd string, args []string) int {
+	var err error
+	switch cmd {
+	case "serve":
+		runServer()
+		return 0
+	case "moderator":
+		err = cliModerator(args)
+	case "user":
+		err = cliUser(args)
+	case "content":
+		err = cliContent(args)
+	case "trash":
+		err = cliTrash(args)
+	case "stats":
+		err = cliStats(args)
+	case "check-config":
+		err = cliCheckConfig()
+	case "help", "-h", "--help":
+		fmt.Print(CLI_USAGE)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, CLI_USAGE)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// withDb apre una connessione al database per la durata del comando
+func withDb(fn func(db *pgx.Conn) error) error {
+	return withConnection(makeDbaseConnection, fn)
+}
+
+// withReadDb è come withDb ma può usare la replica (solo letture)
+func withReadDb(fn func(db *pgx.Conn) error) error {
+	return withConnection(makeReadConnection, fn)
+}
+
+func withConnection(connect func() (*pgx.Conn, error), fn func(db *pgx.Conn) error) error {
+	db, err := connect()
+	if err != nil {
+		return fmt.Errorf("cannot connect to database: %w", err)
+	}
+	defer db.Close(context.Background())
+	return fn(db)
+}
+
+// readPassword usa il flag se valorizzato, altrimenti legge una riga da stdin
+func readPassword(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("cannot read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+	return password, nil
+}
+
+func cliModerator(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing moderator subcommand (add, passwd, disable, enable, promote, demote, list)")
+	}
+
+	fs := flag.NewFlagSet("moderator "+args[0], flag.ContinueOnError)
+	password := fs.String("password", "", "moderator password")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	rest := fs.Args()
+
+	switch args[0] {
+	case "add":
+		if len(rest) < 2 {
+			return fmt.Errorf("usage: moderator add [-password P] <username> <name>")
+		}
+		passwd, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+		return withDb(func(db *pgx.Conn) error {
+			if err := InsertModerator(db, rest[0], hashPassword(passwd), strings.Join(rest[1:], " ")); err != nil {
+				return err
+			}
+			fmt.Printf("Moderator %s created\n", rest[0])
+			return nil
+		})
+
+	case "passwd":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: moderator passwd [-password P] <username>")
+		}
+		passwd, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+		return withDb(func(db *pgx.Conn) error {
+			if err := UpdateModeratorPassword(db, rest[0], hashPassword(passwd)); err != nil {
+				return err
+			}
+			fmt.Printf("Password updated for %s\n", rest[0])
+			return nil
+		})
+
+	case "disable", "enable":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: moderator %s <username>", args[0])
+		}
+		disabled := args[0] == "disable"
+		return withDb(func(db *pgx.Conn) error {
+			if err := SetModeratorDisabled(db, rest[0], disabled); err != nil {
+				return err
+			}
+			fmt.Printf("Moderator %s %sd\n", rest[0], args[0])
+			return nil
+		})
+
+	case "promote", "demote":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: moderator %s <username>", args[0])
+		}
+		admin := args[0] == "promote"
+		return withDb(func(db *pgx.Conn) error {
+			if err := SetModeratorAdmin(db, rest[0], admin); err != nil {
+				return err
+			}
+			fmt.Printf("Moderator %s %sd\n", rest[0], args[0])
+			return nil
+		})
+
+	case "list":
+		return withDb(func(db *pgx.Conn) error {
+			rows, err := QueryAllModerators(db)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			fmt.Printf("%-5s %-24s %-30s %-9s %s\n", "ID", "USERNAME", "NAME", "STATUS", "ADMIN")
+			for rows.Next() {
+				var m ModeratorAccount
+				if err := rows.Scan(&m.ID, &m.Username, &m.Name, &m.IsAdmin, &m.Disabled, &m.CreatedAt); err != nil {
+					return err
+				}
+				status := "active"
+				if m.Disabled {
+					status = "disabled"
+				}
+				admin := ""
+				if m.IsAdmin {
+					admin = "yes"
+				}
+				fmt.Printf("%-5d %-24s %-30s %-9s %s\n", m.ID, m.Username, m.Name, status, admin)
+			}
+			return rows.Err()
+		})
+	}
+
+	return fmt.Errorf("unknown moderator subcommand %q", args[0])
+}
+
+func cliUser(args []string) error {
+	if len(args) != 3 || args[0] != "set-role" {
+		return fmt.Errorf("usage: user set-role <user_id> <user|representative>")
+	}
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q", args[1])
+	}
+	if !validUserRoles[args[2]] {
+		return fmt.Errorf("role must be: user or representative")
+	}
+
+	return withDb(func(db *pgx.Conn) error {
+		err := runAudited(db, cliAuditEntry("set_role", "user", id), func(tx pgx.Tx) (any, any, error) {
+			before, err := QueryUserRole(tx, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := SetUserRole(tx, id, args[2]); err != nil {
+				return nil, nil, err
+			}
+			return map[string]any{"role": before}, map[string]any{"role": args[2]}, nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("User %d is now %s\n", id, args[2])
+		return nil
+	})
+}
+
+func cliContent(args []string) error {
+	usage := fmt.Errorf("usage: content approve|reject|delete|restore|dismiss-reports [-reason CODE] [-note TEXT] <%s> <id>", strings.Join(contentKindNames(), "|"))
+	if len(args) < 1 {
+		return usage
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("content "+action, flag.ContinueOnError)
+	reasonCode := fs.String("reason", "", "rejection reason code (reject only)")
+	noteText := fs.String("note", "", "free-text rejection note (reject only)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usage
+	}
+
+	kind, ok := CONTENT_KINDS[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("content type must be one of: %s", strings.Join(contentKindNames(), ", "))
+	}
+	id, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("invalid ID %q", fs.Arg(1))
+	}
+
+	return withDb(func(db *pgx.Conn) error {
+		entry := cliAuditEntry(action, kind.Name, id)
+		switch action {
+		case "approve":
+			err = auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+				return ApproveContent(tx, kind, id)
+			})
+		case "reject":
+			reason, note, rerr := resolveRejection(db, *reasonCode, *noteText)
+			if rerr != nil {
+				return rerr
+			}
+			err = auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+				return RejectContent(tx, kind, id, reason, note)
+			})
+		case "delete":
+			var result DeleteResult
+			result, err = auditedDelete(db, entry, kind, id)
+			if err == nil {
+				fmt.Printf("Moved to trash with %d likes and %d reports\n", result.LikesDeleted, result.ReportsDeleted)
+			}
+		case "restore":
+			var result RestoreResult
+			result, err = auditedRestore(db, entry, kind, id)
+			if err == nil {
+				fmt.Printf("Restored %d likes and %d reports\n", result.LikesRestored, result.ReportsRestored)
+			}
+		case "dismiss-reports":
+			var dismissed int64
+			dismissed, err = auditedDismissReports(db, cliAuditEntry("dismiss_reports", kind.Name, id), kind, id)
+			if err == nil {
+				fmt.Printf("Dismissed %d reports\n", dismissed)
+			}
+		default:
+			return fmt.Errorf("action must be: approve, reject, delete, restore or dismiss-reports")
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %d: %s done\n", kind.Name, id, action)
+		return nil
+	})
+}
+
+func cliTrash(args []string) error {
+	if len(args) != 1 || args[0] != "purge" {
+		return fmt.Errorf("usage: trash purge")
+	}
+	return withDb(func(db *pgx.Conn) error {
+		count, err := purgeExpiredTrash(db)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Purged %d items older than %d days\n", count, int(trashRetention().Hours()/24))
+		return nil
+	})
+}
+
+func cliStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	asJson := fs.Bool("json", false, "print the full statistics as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return withReadDb(func(db *pgx.Conn) error {
+		stats, err := collectStatistics(db)
+		if err != nil {
+			return err
+		}
+
+		if *asJson {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		}
+
+		t := stats.Totals
+		fmt.Printf("Users:            %d\n", t.TotalUsers)
+		fmt.Printf("Posts:            %d (%d approved)\n", t.TotalPosts, t.ApprovedPosts)
+		fmt.Printf("Spotted:          %d (%d approved)\n", t.TotalSpotted, t.ApprovedSpotted)
+		fmt.Printf("Interactions:     %d\n", t.TotalInteractions)
+		fmt.Printf("Cities / Schools: %d / %d\n", t.TotalCities, t.TotalSchools)
+		return nil
+	})
+}
+
+func cliCheckConfig() error {
+	if problems := validateConfig(); len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, "  -", p)
+		}
+		return fmt.Errorf("%s has %d problem(s)", CONFIG_PATH, len(problems))
+	}
+	fmt.Printf("%s is valid\n", CONFIG_PATH)
+
+	err := withDb(func(db *pgx.Conn) error {
+		if err := db.Ping(context.Background()); err != nil {
+			return fmt.Errorf("database ping failed: %w", err)
+		}
+		fmt.Printf("Database %s@%s:%d/%s reachable\n", CONF.DBASE_USER, CONF.DBASE_HOST, CONF.DBASE_PORT, CONF.DBASE_NAME)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// La replica è opzionale: un problema qui è solo un avviso
+	if CONF.REPLICA_HOST != "" {
+		if db := replicaConnection(); db != nil {
+			db.Close(context.Background())
+			fmt.Printf("Replica %s reachable and within %s lag\n", CONF.REPLICA_HOST, replicaMaxLag())
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: replica %s unusable, reads will use the primary\n", CONF.REPLICA_HOST)
+		}
+	}
+	return nil
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/goccy/go-yaml"
 )
 
+const CONFIG_PATH = "conf.yaml"
+
 type Config struct {
 	HOST        string `yaml:"host"`
 	PORT        int    `yaml:"port"`
@@ -15,17 +18,129 @@ type Config struct {
 	DBASE_USER  string `yaml:"dbase_user"`
 	DBASE_PASSWD string `yaml:"dbase_passwd"`
 	JWT_SECRET  string `yaml:"jwt_secret"`
+
+	// Se vero, ogni rifiuto deve indicare un motivo del catalogo
+	REQUIRE_REJECTION_REASON bool `yaml:"require_rejection_reason"`
+
+	// Giorni dopo i quali gli elementi nel cestino vengono eliminati (default 30)
+	TRASH_RETENTION_DAYS int `yaml:"trash_retention_days"`
+
+	// Minuti per cui un moderatore tiene riservati gli elementi presi dalla
+	// coda (default 15)
+	CLAIM_LEASE_MINUTES int `yaml:"claim_lease_minutes"`
+
+	// Soglie di segnalazioni oltre le quali un elemento approvato viene
+	// nascosto automaticamente; AUTO_HIDE_KINDS le ridefinisce per tipo
+	// (post, spotted). Tutti i valori a zero disattivano la funzione.
+	AUTO_HIDE       AutoHideThreshold            `yaml:"auto_hide"`
+	AUTO_HIDE_KINDS map[string]AutoHideThreshold `yaml:"auto_hide_kinds"`
+
+	// Tipi di dato personale (phone, email, social_handle, codice_fiscale,
+	// address) che segnalano automaticamente gli elementi in attesa o
+	// segnalati; vuoto disattiva la segnalazione automatica
+	PII_AUTO_FLAG []string `yaml:"pii_auto_flag"`
+
+	// Un autore che invia almeno ITEMS elementi in WINDOW_MINUTES minuti
+	// viene segnalato come raffica; zero disattiva il controllo
+	BURST BurstThreshold `yaml:"burst"`
+
+	// Se vero, gli elementi in attesa con lo stesso testo normalizzato di un
+	// contenuto già rifiutato vengono rifiutati con lo stesso motivo
+	AUTO_REJECT_RESUBMISSIONS bool `yaml:"auto_reject_resubmissions"`
+
+	// Proxy (IP o CIDR) di cui fidarsi per X-Forwarded-For; vuoto usa
+	// sempre l'indirizzo della connessione
+	TRUSTED_PROXIES []string `yaml:"trusted_proxies"`
+
+	// Replica in sola lettura (opzionale). Nome, utente e password vuoti
+	// ereditano quelli del primario.
+	REPLICA_HOST            string `yaml:"replica_host"`
+	REPLICA_PORT            int    `yaml:"replica_port"`
+	REPLICA_NAME            string `yaml:"replica_name"`
+	REPLICA_USER            string `yaml:"replica_user"`
+	REPLICA_PASSWD          string `yaml:"replica_passwd"`
+	REPLICA_MAX_LAG_SECONDS int    `yaml:"replica_max_lag_seconds"`
+}
+
+// AutoHideThreshold: un elemento viene nascosto quando le segnalazioni aperte
+// superano REPORTS oppure quando più di DISTINCT_REPORTERS utenti diversi lo
+// segnalano nelle ultime WINDOW_HOURS ore. Un valore a zero disattiva il
+// relativo criterio (nelle soglie per tipo, eredita quello globale).
+type AutoHideThreshold struct {
+	REPORTS            int `yaml:"reports"`
+	DISTINCT_REPORTERS int `yaml:"distinct_reporters"`
+	WINDOW_HOURS       int `yaml:"window_hours"`
+}
+
+type BurstThreshold struct {
+	ITEMS          int `yaml:"items"`
+	WINDOW_MINUTES int `yaml:"window_minutes"`
 }
 
 var CONF Config
 
-func init() {
-	data, err := os.ReadFile("conf.yaml")
+func loadConfig(path string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		panic("Cannot read conf.yaml: " + err.Error())
+		return fmt.Errorf("cannot read %s: %w", path, err)
 	}
-	err = yaml.Unmarshal(data, &CONF)
-	if err != nil {
-		panic("Cannot parse conf.yaml: " + err.Error())
+	if err := yaml.Unmarshal(data, &CONF); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// validateConfig restituisce l'elenco dei problemi trovati nella configurazione
+func validateConfig() []string {
+	var problems []string
+	if CONF.PORT <= 0 || CONF.PORT > 65535 {
+		problems = append(problems, "port must be between 1 and 65535")
+	}
+	if CONF.DBASE_HOST == "" {
+		problems = append(problems, "dbase_host is required")
+	}
+	if CONF.DBASE_PORT <= 0 || CONF.DBASE_PORT > 65535 {
+		problems = append(problems, "dbase_port must be between 1 and 65535")
+	}
+	if CONF.DBASE_NAME == "" {
+		problems = append(problems, "dbase_name is required")
+	}
+	if CONF.DBASE_USER == "" {
+		problems = append(problems, "dbase_user is required")
+	}
+	if CONF.REPLICA_HOST != "" && (CONF.REPLICA_PORT < 0 || CONF.REPLICA_PORT > 65535) {
+		problems = append(problems, "replica_port must be between 1 and 65535")
+	}
+	if CONF.TRASH_RETENTION_DAYS < 0 {
+		problems = append(problems, "trash_retention_days cannot be negative")
+	}
+	if CONF.CLAIM_LEASE_MINUTES < 0 {
+		problems = append(problems, "claim_lease_minutes cannot be negative")
+	}
+	if CONF.AUTO_HIDE.REPORTS < 0 || CONF.AUTO_HIDE.DISTINCT_REPORTERS < 0 || CONF.AUTO_HIDE.WINDOW_HOURS < 0 {
+		problems = append(problems, "auto_hide values cannot be negative")
+	}
+	for name, t := range CONF.AUTO_HIDE_KINDS {
+		if _, ok := CONTENT_KINDS[name]; !ok {
+			problems = append(problems, fmt.Sprintf("auto_hide_kinds: unknown content kind %q", name))
+		}
+		if t.REPORTS < 0 || t.DISTINCT_REPORTERS < 0 || t.WINDOW_HOURS < 0 {
+			problems = append(problems, fmt.Sprintf("auto_hide_kinds.%s values cannot be negative", name))
+		}
+	}
+	for _, t := range CONF.PII_AUTO_FLAG {
+		if !validPIITypes[t] {
+			problems = append(problems, fmt.Sprintf("pii_auto_flag: unknown type %q", t))
+		}
+	}
+	if CONF.BURST.ITEMS < 0 || CONF.BURST.WINDOW_MINUTES < 0 {
+		problems = append(problems, "burst values cannot be negative")
+	}
+	if CONF.REPLICA_MAX_LAG_SECONDS < 0 {
+		problems = append(problems, "replica_max_lag_seconds cannot be negative")
+	}
+	if len(CONF.JWT_SECRET) < 32 {
+		problems = append(problems, "jwt_secret must be at least 32 characters")
 	}
+	return problems
 }
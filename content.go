@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentKind descrive un tipo di contenuto moderabile (post, spotted, ...).
+// Tutte le query e gli handler di moderazione sono scritti una volta sola
+// e parametrizzati su questa struttura; per aggiungere un nuovo tipo basta
+// registrarlo in CONTENT_KINDS.
+type ContentKind struct {
+	Name        string // nome usato nelle route (/api/content/:kind) e nei codici d'errore
+	Label       string // nome leggibile per i messaggi
+	Table       string // tabella principale
+	Alias       string // alias SQL della tabella principale
+	LikeTable   string
+	ReportTable string
+	FKColumn    string // colonna che punta all'elemento in like e segnalazioni
+
+	// Colonne aggiuntive specifiche del tipo (es. visibilità dello spotted),
+	// con le relative JOIN. Se HasVisibility è vero le colonne sono
+	// visibility, color, visibility_desc nell'ordine.
+	HasVisibility bool
+	ExtraColumns  string
+	ExtraJoins    string
+}
+
+var POST_KIND = &ContentKind{
+	Name:        "post",
+	Label:       "Post",
+	Table:       "post",
+	Alias:       "p",
+	LikeTable:   "post_like",
+	ReportTable: "reported_post",
+	FKColumn:    "post_id",
+}
+
+var SPOTTED_KIND = &ContentKind{
+	Name:          "spotted",
+	Label:         "Spotted",
+	Table:         "spotted",
+	Alias:         "sp",
+	LikeTable:     "spotted_like",
+	ReportTable:   "reported_spotted",
+	FKColumn:      "spotted_id",
+	HasVisibility: true,
+	ExtraColumns:  "sp.visibility, COALESCE(sp.color, '#6366f1'), sv.description as visibility_desc",
+	ExtraJoins:    "JOIN spotted_visibility sv ON sp.visibility = sv.id",
+}
+
+var CONTENT_KINDS = map[string]*ContentKind{
+	POST_KIND.Name:    POST_KIND,
+	SPOTTED_KIND.Name: SPOTTED_KIND,
+}
+
+// contentKindNames restituisce i nomi dei tipi registrati, in ordine
+func contentKindNames() []string {
+	names := make([]string, 0, len(CONTENT_KINDS))
+	for name := range CONTENT_KINDS {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sql espande i segnaposto di un template SQL con le tabelle del tipo:
+// {t} tabella, {a} alias, {likes} like, {reports} segnalazioni, {fk} colonna FK,
+// {kind} nome del tipo (per le tabelle condivise come content_claims).
+// I valori arrivano solo da CONTENT_KINDS, mai dall'input dell'utente.
+func (k *ContentKind) sql(template string) string {
+	return strings.NewReplacer(
+		"{t}", k.Table,
+		"{a}", k.Alias,
+		"{likes}", k.LikeTable,
+		"{reports}", k.ReportTable,
+		"{fk}", k.FKColumn,
+		"{kind}", k.Name,
+	).Replace(template)
+}
+
+// contentKindAlias fissa il tipo per le route storiche (/api/posts, /api/spotted)
+func contentKindAlias(kind string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Params = append(ctx.Params, gin.Param{Key: "kind", Value: kind})
+		handler(ctx)
+	}
+}
+
+// resolveContentKind legge il tipo dalla route; risponde 404 se sconosciuto
+func resolveContentKind(ctx *gin.Context) (*ContentKind, bool) {
+	kind, ok := CONTENT_KINDS[ctx.Param("kind")]
+	if !ok {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{
+			Status: "error",
+			Error:  "unknown_content_kind",
+			Msg:    "Content kind must be one of: " + strings.Join(contentKindNames(), ", "),
+		})
+		return nil, false
+	}
+	return kind, true
+}
+
+// registerContentRoutes registra la famiglia di route di moderazione.
+// Con kind vuoto il tipo arriva dal parametro :kind del prefisso,
+// altrimenti le route sono alias con tipo fisso.
+func registerContentRoutes(group *gin.RouterGroup, prefix, kind string) {
+	h := func(handler gin.HandlerFunc) gin.HandlerFunc {
+		if kind == "" {
+			return handler
+		}
+		return contentKindAlias(kind, handler)
+	}
+
+	group.GET(prefix, h(handleGetAllContent))
+	group.GET(prefix+"/pending", h(handleGetPendingContent))
+	group.GET(prefix+"/reported", h(handleGetReportedContent))
+	group.GET(prefix+"/search", h(handleSearchContent))
+	group.GET(prefix+"/trash", h(handleGetTrash))
+	group.GET(prefix+"/bursts", h(handleGetBursts))
+	group.GET(prefix+"/scheduled", h(handleGetScheduledContent))
+	group.POST(prefix+"/bulk", h(handleBulkContent))
+	group.POST(prefix+"/pending/next", h(handleClaimNextContent))
+	group.PUT(prefix+"/:id/claim", h(handleClaimContent))
+	group.DELETE(prefix+"/:id/claim", h(handleReleaseClaim))
+	group.PUT(prefix+"/:id/approve", h(handleApproveContent))
+	group.PUT(prefix+"/:id/reject", h(handleRejectContent))
+	group.PUT(prefix+"/:id/status", h(handleSetContentStatus))
+	group.PUT(prefix+"/:id/schedule", h(handleRescheduleContent))
+	group.DELETE(prefix+"/:id/schedule", h(handleCancelSchedule))
+	group.GET(prefix+"/:id", h(handleGetContentItem))
+	group.GET(prefix+"/:id/reports", h(handleGetContentReports))
+	group.GET(prefix+"/:id/similar", h(handleGetSimilarContent))
+	group.PUT(prefix+"/:id/dismiss-reports", h(handleDismissReports))
+	group.PUT(prefix+"/:id/restore", h(handleRestoreContent))
+	group.PUT(prefix+"/:id/content", h(handleEditContent))
+	group.GET(prefix+"/:id/revisions", h(handleGetRevisions))
+	group.GET(prefix+"/:id/revisions/:rev/diff", h(handleGetRevisionDiff))
+	group.PUT(prefix+"/:id/revisions/:rev/revert", h(handleRevertContent))
+	group.DELETE(prefix+"/:id", h(handleDeleteContent))
+}
@@ -1,6 +1,7 @@
 const API_BASE = '/api';
 let token = localStorage.getItem('moderator_token');
 let userRole = localStorage.getItem('moderator_role') || 'full';
+let currentModerator = JSON.parse(localStorage.getItem('moderator') || 'null');
 
 // Helper functions
 async function apiCall(endpoint, method = 'GET', body = null) {
@@ -54,6 +55,8 @@ async function login(e) {
             userRole = data.role || 'full';
             localStorage.setItem('moderator_token', token);
             localStorage.setItem('moderator_role', userRole);
+            currentModerator = data.moderator;
+            localStorage.setItem('moderator', JSON.stringify(currentModerator));
             showDashboard();
         } else {
             errorEl.textContent = data.msg || 'Credenziali non valide';
@@ -68,6 +71,10 @@ function logout() {
     userRole = 'full';
     localStorage.removeItem('moderator_token');
     localStorage.removeItem('moderator_role');
+    localStorage.removeItem('moderator');
+    currentModerator = null;
+    stopEventStream();
+    lastEventId = null;
     document.getElementById('login-page').classList.remove('hidden');
     document.getElementById('dashboard-page').classList.add('hidden');
 
@@ -79,6 +86,11 @@ async function checkAuth() {
     if (!token) return false;
     try {
         const data = await apiCall('/verify');
+        // Permessi aggiornati (es. is_admin cambiato dopo il login)
+        if (data.status === 'ok' && data.moderator) {
+            currentModerator = data.moderator;
+            localStorage.setItem('moderator', JSON.stringify(currentModerator));
+        }
         return data.status === 'ok';
     } catch {
         return false;
@@ -109,6 +121,7 @@ async function showDashboard() {
         if (usersLink) usersLink.classList.add('active');
     } else {
         // Full access - load statistics as default
+        startEventStream();
         await loadStatistics();
     }
 }
@@ -133,9 +146,11 @@ function setupNavigation() {
                 case 'statistics': loadStatistics(); break;
                 case 'cities': loadCities(); break;
                 case 'schools': loadSchools(); break;
-                case 'posts': loadPendingPosts(); loadReportedPosts(); loadAllPosts(); break;
-                case 'spotted': loadPendingSpotted(); loadReportedSpotted(); loadAllSpotted(); break;
+                case 'posts': loadPendingPosts(); loadReportedPosts(); loadAllPosts(); loadScheduledPosts(); loadTrash('/posts', 'trash-posts'); break;
+                case 'spotted': loadPendingSpotted(); loadReportedSpotted(); loadAllSpotted(); loadScheduledSpotted(); loadTrash('/spotted', 'trash-spotted'); break;
+                case 'terms': loadBannedTerms(); break;
                 case 'users': break; // Users loaded on search
+                case 'audit': loadAudit(1); break;
             }
         });
     });
@@ -345,70 +360,254 @@ async function deleteSchool(id) {
     }
 }
 
+// Paginated content lists
+const listCursors = {};
+
+function listQuery(listId, append) {
+    const params = new URLSearchParams();
+    if (append && listCursors[listId]) params.set('cursor', listCursors[listId]);
+    const status = document.getElementById(`${listId}-status`);
+    const sort = document.getElementById(`${listId}-sort`);
+    if (status && status.value) params.set('status', status.value);
+    if (sort && sort.value) params.set('sort', sort.value);
+    const autoHidden = document.getElementById(`${listId}-auto-hidden`);
+    if (autoHidden && autoHidden.checked) params.set('auto_hidden', 'true');
+    const unclaimed = document.getElementById(`${listId}-unclaimed`);
+    if (unclaimed && unclaimed.checked) params.set('unclaimed', 'true');
+    const pii = document.getElementById(`${listId}-pii`);
+    if (pii && pii.checked) params.set('pii_flagged', 'true');
+    const mentions = document.getElementById(`${listId}-mentions`);
+    if (mentions && mentions.checked) params.set('mentions', 'true');
+    const burst = document.getElementById(`${listId}-burst`);
+    if (burst && burst.checked) params.set('burst', 'true');
+    const resubmissions = document.getElementById(`${listId}-resubmissions`);
+    if (resubmissions && resubmissions.checked) params.set('resubmissions', 'true');
+    const held = document.getElementById(`${listId}-held`);
+    if (held && held.checked) params.set('held', 'true');
+    if (listDupGroups[listId]) params.set('dup_group', listDupGroups[listId]);
+    const query = params.toString();
+    return query ? `?${query}` : '';
+}
+
+function setListHtml(container, append, data, loader, html) {
+    const loadMore = container.querySelector('.load-more');
+    if (loadMore) loadMore.remove();
+
+    if (append) {
+        container.insertAdjacentHTML('beforeend', html);
+    } else {
+        container.innerHTML = html;
+    }
+
+    listCursors[container.id] = data.next_cursor;
+    if (data.next_cursor) {
+        const shown = container.querySelectorAll('.card').length;
+        container.insertAdjacentHTML('beforeend', `
+            <div class="load-more">
+                <button class="btn btn-secondary" onclick="${loader}(true)">Carica altri (${shown} di ${formatNumber(data.total)})</button>
+            </div>
+        `);
+    }
+}
+
+// Bulk actions
+const BULK_ACTION_LABELS = { approve: 'approvare', reject: 'rifiutare', delete: 'eliminare', dismiss_reports: 'archiviare le segnalazioni di' };
+
+function selectedIds(listId) {
+    return Array.from(document.querySelectorAll(`#${listId} .bulk-select:checked`)).map(cb => parseInt(cb.value));
+}
+
+function updateSelectedCount(listId) {
+    const counter = document.getElementById(`${listId}-selected`);
+    if (counter) counter.textContent = `${selectedIds(listId).length} selezionati`;
+}
+
+function toggleSelectAll(listId, checked) {
+    document.querySelectorAll(`#${listId} .bulk-select`).forEach(cb => cb.checked = checked);
+    updateSelectedCount(listId);
+}
+
+async function bulkAction(path, listId, action, loader) {
+    const ids = selectedIds(listId);
+    if (ids.length === 0) {
+        alert('Nessun elemento selezionato');
+        return;
+    }
+    if (action === 'reject') {
+        openRejectModal(rejection => runBulkAction(path, listId, action, loader, { ids, ...rejection }));
+        return;
+    }
+    if (!confirm(`Vuoi ${BULK_ACTION_LABELS[action]} ${ids.length} elementi?`)) return;
+    await runBulkAction(path, listId, action, loader, { ids });
+}
+
+async function runBulkAction(path, listId, action, loader, body) {
+    try {
+        const data = await apiCall(`${path}/bulk`, 'POST', { action, ...body });
+        if (data.status === 'error') {
+            alert(data.msg);
+        } else if (data.failed > 0) {
+            alert(`${data.succeeded} elementi elaborati, ${data.failed} errori`);
+        }
+    } catch (err) {
+        alert('Errore nell\'azione massiva');
+    }
+
+    const selectAll = document.getElementById(`${listId}-select-all`);
+    if (selectAll) selectAll.checked = false;
+    await window[loader]();
+    updateSelectedCount(listId);
+}
+
+document.addEventListener('change', (e) => {
+    if (e.target.classList.contains('bulk-select')) {
+        updateSelectedCount(e.target.closest('.cards-list').id);
+    }
+});
+
 // Posts
-async function loadPendingPosts() {
+// Claims
+function isMyClaim(item) {
+    return currentModerator && item.claimed_by === currentModerator.id;
+}
+
+function claimBadge(item) {
+    if (!item.claimed_by) return '';
+    const until = new Date(item.claim_expires_at).toLocaleTimeString('it-IT', { hour: '2-digit', minute: '2-digit' });
+    if (isMyClaim(item)) return `<span class="badge badge-success">Riservato a te fino alle ${until}</span>`;
+    return `<span class="badge badge-danger">Riservato da ${escapeHtml(item.claimed_by_name || '')} fino alle ${until}</span>`;
+}
+
+function claimButtons(path, item, loader) {
+    if (isMyClaim(item)) {
+        return `<button class="btn btn-secondary btn-small" onclick="releaseClaim('${path}', ${item.id}, '${loader}')">Rilascia</button>`;
+    }
+    if (!item.claimed_by) {
+        return `<button class="btn btn-secondary btn-small" onclick="claimItem('${path}', ${item.id}, '${loader}')">Riserva</button>`;
+    }
+    if (currentModerator && currentModerator.is_admin) {
+        return `<button class="btn btn-secondary btn-small" onclick="claimItem('${path}', ${item.id}, '${loader}', true)">Prendi in carico</button>
+            <button class="btn btn-secondary btn-small" onclick="releaseClaim('${path}', ${item.id}, '${loader}', true)">Forza rilascio</button>`;
+    }
+    return '';
+}
+
+async function claimItem(path, id, loader, steal = false) {
+    const data = await apiCall(`${path}/${id}/claim${steal ? '?steal=true' : ''}`, 'PUT');
+    if (data.status !== 'ok') alert(data.msg);
+    await window[loader]();
+}
+
+async function releaseClaim(path, id, loader, force = false) {
+    const data = await apiCall(`${path}/${id}/claim${force ? '?force=true' : ''}`, 'DELETE');
+    if (data.status !== 'ok') alert(data.msg);
+    await window[loader]();
+}
+
+// claimNext riserva i prossimi elementi e mostra solo quelli nella lista
+async function claimNext(path, listId, renderer) {
+    const data = await apiCall(`${path}/pending/next?count=10`, 'POST');
+    const container = document.getElementById(listId);
+    if (data.status !== 'ok') {
+        alert(data.msg);
+        return;
+    }
+    if (data.data.length === 0) {
+        container.innerHTML = '<div class="empty-state"><p>Nessun elemento libero in attesa</p></div>';
+        return;
+    }
+    listCursors[listId] = null;
+    container.innerHTML = data.data.map(window[renderer]).join('');
+}
+
+function renderPendingPost(post) {
+    return `
+        <div class="card">
+            <div class="card-header">
+                <div>
+                    <input type="checkbox" class="bulk-select" value="${post.id}">
+                    ${creatorLink(post)}
+                    <span class="badge badge-warning">In attesa</span>
+                    ${autoHiddenBadge(post)}
+                    ${claimBadge(post)}
+                    ${editedBadge(post)}
+                    ${piiBadge(post)}
+                    ${mentionBadge(post)}
+                    ${burstBadge(post)}
+                    ${resubmissionBadge(post)}
+                    ${heldBadge(post)}
+                    ${dupGroupBadge(post, 'pending-posts-list', 'loadPendingPosts')}
+                </div>
+            </div>
+            <div class="card-meta">
+                <span>${post.school_name || 'N/A'} - ${post.city_name || 'N/A'}</span>
+                <span>${formatDate(post.creation_timestamp)}</span>
+            </div>
+            <div class="card-content">${highlightTerms(post.content, post.term_matches, post.pii_matches, post.mentions)}</div>
+            ${mentionLinks(post)}
+            ${resubmissionInfo(post)}
+            <div class="card-actions">
+                <button class="btn btn-success btn-small" onclick="approvePost(${post.id})">Approva</button>
+                <button class="btn btn-secondary btn-small" onclick="openScheduleModal('/posts', ${post.id}, 'loadPendingPosts')">Programma</button>
+                <button class="btn btn-danger btn-small" onclick="rejectPost(${post.id})">Rifiuta</button>
+                <button class="btn btn-secondary btn-small" onclick="confirmDeletePost(${post.id})">Elimina</button>
+                <button class="btn btn-secondary btn-small" onclick="openEditModal('/posts', ${post.id}, 'pending-posts-list', 'loadPendingPosts')">Modifica</button>
+                ${claimButtons('/posts', post, 'loadPendingPosts')}
+            </div>
+        </div>
+    `;
+}
+
+async function loadPendingPosts(append = false) {
     try {
-        const data = await apiCall('/posts/pending');
+        const data = await apiCall(`/posts/pending${listQuery('pending-posts-list', append)}`);
         const container = document.getElementById('pending-posts-list');
 
-        if (!data.data || data.data.length === 0) {
+        if (!append && (!data.data || data.data.length === 0)) {
             container.innerHTML = '<div class="empty-state"><p>Nessun post in attesa di approvazione</p></div>';
             return;
         }
 
-        container.innerHTML = data.data.map(post => `
-            <div class="card">
-                <div class="card-header">
-                    <div>
-                        <strong>${post.creator_first_name} ${post.creator_last_name}</strong>
-                        <span class="badge badge-warning">In attesa</span>
-                    </div>
-                </div>
-                <div class="card-meta">
-                    <span>${post.school_name || 'N/A'} - ${post.city_name || 'N/A'}</span>
-                    <span>${formatDate(post.creation_timestamp)}</span>
-                </div>
-                <div class="card-content">${post.content}</div>
-                <div class="card-actions">
-                    <button class="btn btn-success btn-small" onclick="approvePost(${post.id})">Approva</button>
-                    <button class="btn btn-danger btn-small" onclick="rejectPost(${post.id})">Rifiuta</button>
-                    <button class="btn btn-secondary btn-small" onclick="confirmDeletePost(${post.id})">Elimina</button>
-                </div>
-            </div>
-        `).join('');
+        setListHtml(container, append, data, 'loadPendingPosts', (data.data || []).map(renderPendingPost).join(''));
     } catch (err) {
         console.error('Error loading pending posts:', err);
     }
 }
 
-async function loadReportedPosts() {
+async function loadReportedPosts(append = false) {
     try {
-        const data = await apiCall('/posts/reported');
+        const data = await apiCall(`/posts/reported${listQuery('reported-posts-list', append)}`);
         const container = document.getElementById('reported-posts-list');
 
-        if (!data.data || data.data.length === 0) {
+        if (!append && (!data.data || data.data.length === 0)) {
             container.innerHTML = '<div class="empty-state"><p>Nessun post segnalato</p></div>';
             return;
         }
 
-        container.innerHTML = data.data.map(post => `
+        setListHtml(container, append, data, 'loadReportedPosts', (data.data || []).map(post => `
             <div class="card">
                 <div class="card-header">
                     <div>
-                        <strong>${post.creator_first_name} ${post.creator_last_name}</strong>
+                        <input type="checkbox" class="bulk-select" value="${post.id}">
+                        ${creatorLink(post)}
                         <span class="badge badge-danger">${post.report_count} segnalazioni</span>
+                        ${editedBadge(post)}
+                        ${piiBadge(post)}
                     </div>
                 </div>
                 <div class="card-meta">
                     <span>${post.school_name || 'N/A'} - ${post.city_name || 'N/A'}</span>
                     <span>${formatDate(post.creation_timestamp)}</span>
                 </div>
-                <div class="card-content">${post.content}</div>
+                <div class="card-content">${highlightTerms(post.content, null, post.pii_matches)}</div>
                 <div class="card-actions">
+                    <button class="btn btn-success btn-small" onclick="dismissReports('/posts', ${post.id}, loadReportedPosts)">Archivia segnalazioni</button>
+                    <button class="btn btn-secondary btn-small" onclick="showReports('/posts', ${post.id})">Dettagli segnalazioni</button>
+                    <button class="btn btn-secondary btn-small" onclick="openEditModal('/posts', ${post.id}, 'reported-posts-list', 'loadReportedPosts')">Modifica</button>
                     <button class="btn btn-danger btn-small" onclick="confirmDeletePost(${post.id})">Elimina</button>
                 </div>
             </div>
-        `).join('');
+        `).join(''));
     } catch (err) {
         console.error('Error loading reported posts:', err);
     }
@@ -416,24 +615,24 @@ async function loadReportedPosts() {
 
 async function approvePost(id) {
     try {
-        await apiCall(`/posts/${id}/approve`, 'PUT');
+        const data = await apiCall(`/posts/${id}/approve`, 'PUT');
+        if (data.status === 'error') alert(data.msg);
         await loadPendingPosts();
     } catch (err) {
         alert('Errore nell\'approvazione');
     }
 }
 
-async function rejectPost(id) {
-    try {
-        await apiCall(`/posts/${id}/reject`, 'PUT');
+function rejectPost(id) {
+    openRejectModal(async (rejection) => {
+        const data = await apiCall(`/posts/${id}/reject`, 'PUT', rejection);
+        if (data.status === 'error') throw new Error(data.msg);
         await loadPendingPosts();
-    } catch (err) {
-        alert('Errore nel rifiuto');
-    }
+    });
 }
 
 function confirmDeletePost(id) {
-    document.getElementById('confirm-message').textContent = 'Sei sicuro di voler eliminare questo post?';
+    document.getElementById('confirm-message').textContent = 'Spostare questo post nel cestino? Potrai ripristinarlo fino all\'eliminazione definitiva.';
     document.getElementById('confirm-delete-btn').onclick = () => deletePost(id);
     document.getElementById('confirm-modal').classList.remove('hidden');
 }
@@ -445,11 +644,75 @@ async function deletePost(id) {
         await loadPendingPosts();
         await loadReportedPosts();
         await loadAllPosts();
+        await loadTrash('/posts', 'trash-posts');
     } catch (err) {
         alert('Errore nell\'eliminazione');
     }
 }
 
+// Rejection reasons
+let rejectionReasons = null;
+
+async function loadRejectionReasons() {
+    if (rejectionReasons) return rejectionReasons;
+    const data = await apiCall('/rejection-reasons');
+    rejectionReasons = data.data || [];
+    return rejectionReasons;
+}
+
+// Elementi approvati tornati in attesa per troppe segnalazioni
+function autoHiddenBadge(item) {
+    if (!item.auto_hidden_at || item.status !== 'received') return '';
+    return `<span class="badge badge-danger" title="Nascosto il ${formatDate(item.auto_hidden_at)}">Nascosto automaticamente (${item.report_count} segnalazioni)</span>`;
+}
+
+function rejectionInfo(item) {
+    if (item.status !== 'rejected' || (!item.rejection_reason && !item.rejection_note)) return '';
+    const label = item.rejection_reason_label || item.rejection_reason || 'Senza motivo';
+    const note = item.rejection_note ? ` — ${escapeHtml(item.rejection_note)}` : '';
+    return `<div class="rejection-info"><strong>Motivo:</strong> ${escapeHtml(label)}${note}</div>`;
+}
+
+// openRejectModal chiede motivo e nota e li passa a onConfirm({ reason, note })
+async function openRejectModal(onConfirm) {
+    let reasons;
+    try {
+        reasons = await loadRejectionReasons();
+    } catch (err) {
+        reasons = [];
+    }
+
+    const select = document.getElementById('reject-reason');
+    const desc = document.getElementById('reject-reason-desc');
+    select.innerHTML = '<option value="">Nessun motivo</option>' +
+        reasons.map(r => `<option value="${r.code}">${escapeHtml(r.label)}</option>`).join('');
+    select.onchange = () => {
+        const reason = reasons.find(r => r.code === select.value);
+        desc.textContent = reason ? reason.description : '';
+    };
+    desc.textContent = '';
+    document.getElementById('reject-note').value = '';
+
+    document.getElementById('reject-form').onsubmit = async (e) => {
+        e.preventDefault();
+        const rejection = {
+            reason: select.value,
+            note: document.getElementById('reject-note').value.trim()
+        };
+        try {
+            await onConfirm(rejection);
+            closeRejectModal();
+        } catch (err) {
+            alert(err.message || 'Errore nel rifiuto');
+        }
+    };
+    document.getElementById('reject-modal').classList.remove('hidden');
+}
+
+function closeRejectModal() {
+    document.getElementById('reject-modal').classList.add('hidden');
+}
+
 function getStatusBadge(status) {
     const badges = {
         'received': '<span class="badge badge-warning">In attesa</span>',
@@ -459,24 +722,25 @@ function getStatusBadge(status) {
     return badges[status] || status;
 }
 
-async function loadAllPosts() {
+async function loadAllPosts(append = false) {
     try {
-        const data = await apiCall('/posts');
+        const data = await apiCall(`/posts${listQuery('all-posts-list', append)}`);
         const container = document.getElementById('all-posts-list');
 
-        if (!data.data || data.data.length === 0) {
+        if (!append && (!data.data || data.data.length === 0)) {
             container.innerHTML = '<div class="empty-state"><p>Nessun post trovato</p></div>';
             return;
         }
 
-        container.innerHTML = data.data.map(post => `
+        setListHtml(container, append, data, 'loadAllPosts', (data.data || []).map(post => `
             <div class="card">
                 <div class="card-header">
                     <div>
-                        <strong>${post.creator_first_name} ${post.creator_last_name}</strong>
+                        ${creatorLink(post)}
                         ${getStatusBadge(post.status)}
                     </div>
                 </div>
+                ${rejectionInfo(post)}
                 <div class="card-meta">
                     <span>Email: ${post.creator_email}</span>
                     <span>${post.school_name || 'N/A'} - ${post.city_name || 'N/A'}</span>
@@ -493,7 +757,7 @@ async function loadAllPosts() {
                     <button class="btn btn-danger btn-small" onclick="confirmDeletePost(${post.id})">Elimina</button>
                 </div>
             </div>
-        `).join('');
+        `).join(''));
     } catch (err) {
         console.error('Error loading all posts:', err);
     }
@@ -501,6 +765,15 @@ async function loadAllPosts() {
 
 async function setPostStatus(id, status) {
     if (!status) return;
+    if (status === 'rejected') {
+        openRejectModal(async (rejection) => {
+            const data = await apiCall(`/posts/${id}/status`, 'PUT', { status, ...rejection });
+        if (data.status === 'error') throw new Error(data.msg);
+            await loadAllPosts();
+            await loadPendingPosts();
+        });
+        return;
+    }
     try {
         await apiCall(`/posts/${id}/status`, 'PUT', { status });
         await loadAllPosts();
@@ -511,58 +784,80 @@ async function setPostStatus(id, status) {
 }
 
 // Spotted
-async function loadPendingSpotted() {
+function renderPendingSpotted(s) {
+    return `
+        <div class="card" style="border-left: 4px solid ${s.color || '#6366f1'}">
+            <div class="card-header">
+                <div>
+                    <input type="checkbox" class="bulk-select" value="${s.id}">
+                    ${creatorLink(s)}
+                    <span class="badge badge-warning">${s.visibility_desc}</span>
+                    ${autoHiddenBadge(s)}
+                    ${claimBadge(s)}
+                    ${editedBadge(s)}
+                    ${piiBadge(s)}
+                    ${mentionBadge(s)}
+                    ${burstBadge(s)}
+                    ${resubmissionBadge(s)}
+                    ${heldBadge(s)}
+                    ${dupGroupBadge(s, 'pending-spotted-list', 'loadPendingSpotted')}
+                </div>
+            </div>
+            <div class="card-meta">
+                <span>Email: ${s.creator_email}</span>
+                <span>${s.school_name || 'N/A'} - ${s.city_name || 'N/A'}</span>
+                <span>${formatDate(s.creation_timestamp)}</span>
+            </div>
+            <div class="card-content">${highlightTerms(s.content, s.term_matches, s.pii_matches, s.mentions)}</div>
+            ${mentionLinks(s)}
+            ${resubmissionInfo(s)}
+            <div class="card-actions">
+                <button class="btn btn-success btn-small" onclick="approveSpotted(${s.id})">Approva</button>
+                <button class="btn btn-secondary btn-small" onclick="openScheduleModal('/spotted', ${s.id}, 'loadPendingSpotted')">Programma</button>
+                <button class="btn btn-danger btn-small" onclick="rejectSpotted(${s.id})">Rifiuta</button>
+                <button class="btn btn-secondary btn-small" onclick="confirmDeleteSpotted(${s.id})">Elimina</button>
+                <button class="btn btn-secondary btn-small" onclick="openEditModal('/spotted', ${s.id}, 'pending-spotted-list', 'loadPendingSpotted')">Modifica</button>
+                ${claimButtons('/spotted', s, 'loadPendingSpotted')}
+            </div>
+        </div>
+    `;
+}
+
+async function loadPendingSpotted(append = false) {
     try {
-        const data = await apiCall('/spotted/pending');
+        const data = await apiCall(`/spotted/pending${listQuery('pending-spotted-list', append)}`);
         const container = document.getElementById('pending-spotted-list');
 
-        if (!data.data || data.data.length === 0) {
+        if (!append && (!data.data || data.data.length === 0)) {
             container.innerHTML = '<div class="empty-state"><p>Nessuno spotted in attesa di approvazione</p></div>';
             return;
         }
 
-        container.innerHTML = data.data.map(s => `
-            <div class="card" style="border-left: 4px solid ${s.color || '#6366f1'}">
-                <div class="card-header">
-                    <div>
-                        <strong>${s.creator_first_name} ${s.creator_last_name}</strong>
-                        <span class="badge badge-warning">${s.visibility_desc}</span>
-                    </div>
-                </div>
-                <div class="card-meta">
-                    <span>Email: ${s.creator_email}</span>
-                    <span>${s.school_name || 'N/A'} - ${s.city_name || 'N/A'}</span>
-                    <span>${formatDate(s.creation_timestamp)}</span>
-                </div>
-                <div class="card-content">${s.content}</div>
-                <div class="card-actions">
-                    <button class="btn btn-success btn-small" onclick="approveSpotted(${s.id})">Approva</button>
-                    <button class="btn btn-danger btn-small" onclick="rejectSpotted(${s.id})">Rifiuta</button>
-                    <button class="btn btn-secondary btn-small" onclick="confirmDeleteSpotted(${s.id})">Elimina</button>
-                </div>
-            </div>
-        `).join('');
+        setListHtml(container, append, data, 'loadPendingSpotted', (data.data || []).map(renderPendingSpotted).join(''));
     } catch (err) {
         console.error('Error loading pending spotted:', err);
     }
 }
 
-async function loadReportedSpotted() {
+async function loadReportedSpotted(append = false) {
     try {
-        const data = await apiCall('/spotted/reported');
+        const data = await apiCall(`/spotted/reported${listQuery('reported-spotted-list', append)}`);
         const container = document.getElementById('reported-spotted-list');
 
-        if (!data.data || data.data.length === 0) {
+        if (!append && (!data.data || data.data.length === 0)) {
             container.innerHTML = '<div class="empty-state"><p>Nessuno spotted segnalato</p></div>';
             return;
         }
 
-        container.innerHTML = data.data.map(s => `
+        setListHtml(container, append, data, 'loadReportedSpotted', (data.data || []).map(s => `
             <div class="card" style="border-left: 4px solid ${s.color || '#6366f1'}">
                 <div class="card-header">
                     <div>
-                        <strong>${s.creator_first_name} ${s.creator_last_name}</strong>
+                        <input type="checkbox" class="bulk-select" value="${s.id}">
+                        ${creatorLink(s)}
                         <span class="badge badge-danger">${s.report_count} segnalazioni</span>
+                        ${editedBadge(s)}
+                        ${piiBadge(s)}
                     </div>
                 </div>
                 <div class="card-meta">
@@ -570,12 +865,15 @@ async function loadReportedSpotted() {
                     <span>${s.school_name || 'N/A'} - ${s.city_name || 'N/A'}</span>
                     <span>${formatDate(s.creation_timestamp)}</span>
                 </div>
-                <div class="card-content">${s.content}</div>
+                <div class="card-content">${highlightTerms(s.content, null, s.pii_matches)}</div>
                 <div class="card-actions">
+                    <button class="btn btn-success btn-small" onclick="dismissReports('/spotted', ${s.id}, loadReportedSpotted)">Archivia segnalazioni</button>
+                    <button class="btn btn-secondary btn-small" onclick="showReports('/spotted', ${s.id})">Dettagli segnalazioni</button>
+                    <button class="btn btn-secondary btn-small" onclick="openEditModal('/spotted', ${s.id}, 'reported-spotted-list', 'loadReportedSpotted')">Modifica</button>
                     <button class="btn btn-danger btn-small" onclick="confirmDeleteSpotted(${s.id})">Elimina</button>
                 </div>
             </div>
-        `).join('');
+        `).join(''));
     } catch (err) {
         console.error('Error loading reported spotted:', err);
     }
@@ -583,24 +881,24 @@ async function loadReportedSpotted() {
 
 async function approveSpotted(id) {
     try {
-        await apiCall(`/spotted/${id}/approve`, 'PUT');
+        const data = await apiCall(`/spotted/${id}/approve`, 'PUT');
+        if (data.status === 'error') alert(data.msg);
         await loadPendingSpotted();
     } catch (err) {
         alert('Errore nell\'approvazione');
     }
 }
 
-async function rejectSpotted(id) {
-    try {
-        await apiCall(`/spotted/${id}/reject`, 'PUT');
+function rejectSpotted(id) {
+    openRejectModal(async (rejection) => {
+        const data = await apiCall(`/spotted/${id}/reject`, 'PUT', rejection);
+        if (data.status === 'error') throw new Error(data.msg);
         await loadPendingSpotted();
-    } catch (err) {
-        alert('Errore nel rifiuto');
-    }
+    });
 }
 
 function confirmDeleteSpotted(id) {
-    document.getElementById('confirm-message').textContent = 'Sei sicuro di voler eliminare questo spotted?';
+    document.getElementById('confirm-message').textContent = 'Spostare questo spotted nel cestino? Potrai ripristinarlo fino all\'eliminazione definitiva.';
     document.getElementById('confirm-delete-btn').onclick = () => deleteSpotted(id);
     document.getElementById('confirm-modal').classList.remove('hidden');
 }
@@ -612,30 +910,32 @@ async function deleteSpotted(id) {
         await loadPendingSpotted();
         await loadReportedSpotted();
         await loadAllSpotted();
+        await loadTrash('/spotted', 'trash-spotted');
     } catch (err) {
         alert('Errore nell\'eliminazione');
     }
 }
 
-async function loadAllSpotted() {
+async function loadAllSpotted(append = false) {
     try {
-        const data = await apiCall('/spotted');
+        const data = await apiCall(`/spotted${listQuery('all-spotted-list', append)}`);
         const container = document.getElementById('all-spotted-list');
 
-        if (!data.data || data.data.length === 0) {
+        if (!append && (!data.data || data.data.length === 0)) {
             container.innerHTML = '<div class="empty-state"><p>Nessuno spotted trovato</p></div>';
             return;
         }
 
-        container.innerHTML = data.data.map(s => `
+        setListHtml(container, append, data, 'loadAllSpotted', (data.data || []).map(s => `
             <div class="card" style="border-left: 4px solid ${s.color || '#6366f1'}">
                 <div class="card-header">
                     <div>
-                        <strong>${s.creator_first_name} ${s.creator_last_name}</strong>
+                        ${creatorLink(s)}
                         ${getStatusBadge(s.status)}
                         <span class="badge">${s.visibility_desc}</span>
                     </div>
                 </div>
+                ${rejectionInfo(s)}
                 <div class="card-meta">
                     <span>Email: ${s.creator_email}</span>
                     <span>${s.school_name || 'N/A'} - ${s.city_name || 'N/A'}</span>
@@ -652,7 +952,7 @@ async function loadAllSpotted() {
                     <button class="btn btn-danger btn-small" onclick="confirmDeleteSpotted(${s.id})">Elimina</button>
                 </div>
             </div>
-        `).join('');
+        `).join(''));
     } catch (err) {
         console.error('Error loading all spotted:', err);
     }
@@ -660,6 +960,15 @@ async function loadAllSpotted() {
 
 async function setSpottedStatus(id, status) {
     if (!status) return;
+    if (status === 'rejected') {
+        openRejectModal(async (rejection) => {
+            const data = await apiCall(`/spotted/${id}/status`, 'PUT', { status, ...rejection });
+        if (data.status === 'error') throw new Error(data.msg);
+            await loadAllSpotted();
+            await loadPendingSpotted();
+        });
+        return;
+    }
     try {
         await apiCall(`/spotted/${id}/status`, 'PUT', { status });
         await loadAllSpotted();
@@ -669,6 +978,589 @@ async function setSpottedStatus(id, status) {
     }
 }
 
+// Report details
+async function showReports(path, id) {
+    const container = document.getElementById('reports-detail');
+    container.innerHTML = '<p>Caricamento...</p>';
+    document.getElementById('reports-modal').classList.remove('hidden');
+
+    try {
+        const data = await apiCall(`${path}/${id}/reports`);
+        if (data.status !== 'ok') {
+            container.innerHTML = `<p>${escapeHtml(data.msg)}</p>`;
+            return;
+        }
+        const r = data.data;
+
+        const warning = r.mass_reporters > 0
+            ? `<div class="mass-report-warning">Attenzione: ${r.mass_reporters} segnalatori con attività anomala (segnalazioni ripetute o troppo frequenti)</div>`
+            : '';
+
+        const reasons = r.by_reason.map(g => `
+            <li>${escapeHtml(g.reason || 'Nessun motivo indicato')}: <strong>${g.count}</strong></li>
+        `).join('');
+
+        const reporters = r.reporters.map(p => `
+            <tr class="${p.mass_reporting ? 'mass-reporter' : ''}">
+                <td>${escapeHtml(p.name)} (#${p.reporter_id})</td>
+                <td>${p.reports_on_item}</td>
+                <td>${p.recent_reports}</td>
+            </tr>
+        `).join('');
+
+        const reports = r.reports.map(rep => `
+            <tr class="${rep.reviewed_at ? 'report-reviewed' : ''}">
+                <td>${formatDate(rep.created_at)}</td>
+                <td>${escapeHtml(rep.reporter_first_name)} ${escapeHtml(rep.reporter_last_name)}<br><small>${escapeHtml(rep.reporter_email)}</small></td>
+                <td>${escapeHtml(rep.reason || '-')}</td>
+                <td>${rep.reviewed_at ? `Archiviata ${formatDate(rep.reviewed_at)}${rep.reviewed_by_name ? ' da ' + escapeHtml(rep.reviewed_by_name) : ''}` : 'Aperta'}</td>
+            </tr>
+        `).join('');
+
+        container.innerHTML = `
+            <p><strong>${r.total}</strong> segnalazioni (${r.open} aperte) da <strong>${r.distinct_reporters}</strong> utenti</p>
+            ${warning}
+            <h4>Per motivo</h4>
+            <ul class="reason-list">${reasons}</ul>
+            <h4>Segnalatori</h4>
+            <table class="stats-table">
+                <thead><tr><th>Utente</th><th>Su questo elemento</th><th>Ultime 24 ore</th></tr></thead>
+                <tbody>${reporters}</tbody>
+            </table>
+            <h4>Segnalazioni</h4>
+            <table class="stats-table">
+                <thead><tr><th>Data</th><th>Segnalatore</th><th>Motivo</th><th>Stato</th></tr></thead>
+                <tbody>${reports}</tbody>
+            </table>
+        `;
+    } catch (err) {
+        container.innerHTML = '<p>Errore nel caricamento delle segnalazioni</p>';
+    }
+}
+
+async function dismissReports(path, id, reload) {
+    if (!confirm('Archiviare le segnalazioni? L\'elemento uscirà dalla coda finché non ne arrivano di nuove.')) return;
+    try {
+        const data = await apiCall(`${path}/${id}/dismiss-reports`, 'PUT');
+        if (data.status === 'error') {
+            alert(data.msg);
+        }
+        await reload();
+    } catch (err) {
+        alert('Errore nell\'archiviazione');
+    }
+}
+
+function closeReportsModal() {
+    document.getElementById('reports-modal').classList.add('hidden');
+}
+
+// Modifica del testo e cronologia delle revisioni
+function editedBadge(item) {
+    if (!item.edited_at) return '';
+    return `<span class="badge" title="Ultima modifica ${formatDate(item.edited_at)}">Modificato</span>`;
+}
+
+function renderDiff(diff) {
+    return diff.map(d => {
+        const text = escapeHtml(d.text);
+        if (d.op === 'insert') return `<ins>${text}</ins>`;
+        if (d.op === 'delete') return `<del>${text}</del>`;
+        return text;
+    }).join('');
+}
+
+async function openEditModal(path, id, listId, loader) {
+    const textarea = document.getElementById('edit-content');
+    const history = document.getElementById('edit-history');
+    const card = document.querySelector(`#${listId} .bulk-select[value="${id}"]`)?.closest('.card');
+    textarea.value = card ? card.querySelector('.card-content').textContent : '';
+    document.getElementById('edit-reason').value = '';
+    history.innerHTML = '<p>Caricamento...</p>';
+
+    document.getElementById('edit-form').onsubmit = async (e) => {
+        e.preventDefault();
+        const data = await apiCall(`${path}/${id}/content`, 'PUT', {
+            content: textarea.value,
+            reason: document.getElementById('edit-reason').value.trim()
+        });
+        if (data.status !== 'ok') {
+            alert(data.msg);
+            return;
+        }
+        closeEditModal();
+        window[loader]();
+    };
+    document.getElementById('edit-modal').classList.remove('hidden');
+
+    try {
+        const data = await apiCall(`${path}/${id}/revisions`);
+        if (data.status !== 'ok') {
+            history.innerHTML = `<p>${escapeHtml(data.msg)}</p>`;
+            return;
+        }
+        const revisions = data.data;
+        if (revisions.length === 0) {
+            history.innerHTML = '<p>Testo mai modificato</p>';
+            return;
+        }
+        textarea.value = revisions[revisions.length - 1].content;
+        history.innerHTML = revisions.slice().reverse().map((r, i) => `
+            <div class="revision">
+                <div class="card-meta">
+                    <span><strong>Revisione ${r.revision}</strong> — ${r.editor_id ? escapeHtml(r.editor_name || '#' + r.editor_id) : 'Testo originale'}</span>
+                    <span>${formatDate(r.created_at)}</span>
+                </div>
+                ${r.reason ? `<div class="revision-reason">${escapeHtml(r.reason)}</div>` : ''}
+                ${r.reverted_from ? `<div class="revision-reason">Ripristino della revisione ${r.reverted_from}</div>` : ''}
+                <div class="revision-diff">${r.diff ? renderDiff(r.diff) : escapeHtml(r.content)}</div>
+                ${i > 0 ? `<button class="btn btn-secondary btn-small" onclick="revertContent('${path}', ${id}, ${r.revision}, '${listId}', '${loader}')">Ripristina</button>` : ''}
+            </div>
+        `).join('');
+    } catch (err) {
+        history.innerHTML = '<p>Errore nel caricamento della cronologia</p>';
+    }
+}
+
+async function revertContent(path, id, revision, listId, loader) {
+    const data = await apiCall(`${path}/${id}/revisions/${revision}/revert`, 'PUT', {
+        reason: document.getElementById('edit-reason').value.trim()
+    });
+    if (data.status !== 'ok') {
+        alert(data.msg);
+        return;
+    }
+    window[loader]();
+    openEditModal(path, id, listId, loader);
+}
+
+function closeEditModal() {
+    document.getElementById('edit-modal').classList.add('hidden');
+}
+
+// Trash
+async function loadTrash(path, prefix, page = 1) {
+    const container = document.getElementById(`${prefix}-list`);
+    const pagination = document.getElementById(`${prefix}-pagination`);
+    try {
+        const data = await apiCall(`${path}/trash?page=${page}`);
+        if (!data.data || data.data.length === 0) {
+            container.innerHTML = '<div class="empty-state"><p>Il cestino è vuoto</p></div>';
+            pagination.innerHTML = '';
+            return;
+        }
+
+        container.innerHTML = data.data.map(item => `
+            <div class="card card-trashed">
+                <div class="card-header">
+                    <div>
+                        <strong>${escapeHtml(item.creator_first_name)} ${escapeHtml(item.creator_last_name)}</strong>
+                        <span class="badge">${item.likes_count} like</span>
+                        <span class="badge">${item.report_count} segnalazioni</span>
+                    </div>
+                </div>
+                <div class="card-meta">
+                    <span>Eliminato ${formatDate(item.deleted_at)}${item.deleted_by_name ? ' da ' + escapeHtml(item.deleted_by_name) : ''}</span>
+                    <span>Eliminazione definitiva: ${formatDate(item.purge_at)}</span>
+                </div>
+                <div class="card-content">${escapeHtml(item.content)}</div>
+                <div class="card-actions">
+                    <button class="btn btn-success btn-small" onclick="restoreContent('${path}', '${prefix}', ${item.id})">Ripristina</button>
+                </div>
+            </div>
+        `).join('');
+
+        const pages = Math.ceil(data.total / data.page_size);
+        pagination.innerHTML = pages > 1 ? `
+            <button class="btn btn-secondary btn-small" ${page <= 1 ? 'disabled' : ''} onclick="loadTrash('${path}', '${prefix}', ${page - 1})">&laquo;</button>
+            <span>Pagina ${page} di ${pages}</span>
+            <button class="btn btn-secondary btn-small" ${page >= pages ? 'disabled' : ''} onclick="loadTrash('${path}', '${prefix}', ${page + 1})">&raquo;</button>
+        ` : '';
+    } catch (err) {
+        console.error('Error loading trash:', err);
+    }
+}
+
+async function restoreContent(path, prefix, id) {
+    try {
+        const data = await apiCall(`${path}/${id}/restore`, 'PUT');
+        if (data.status === 'error') {
+            alert(data.msg);
+            return;
+        }
+        await loadTrash(path, prefix);
+        if (path === '/posts') {
+            loadPendingPosts(); loadReportedPosts(); loadAllPosts();
+        } else {
+            loadPendingSpotted(); loadReportedSpotted(); loadAllSpotted();
+        }
+    } catch (err) {
+        alert('Errore nel ripristino');
+    }
+}
+
+// Search
+function highlightSnippet(snippet) {
+    // Lo snippet arriva con <mark> attorno ai termini: escape di tutto il resto
+    return escapeHtml(snippet).replace(/&lt;(\/?)mark&gt;/g, '<$1mark>');
+}
+
+// Evidenzia i termini vietati: start/end sono posizioni in caratteri Unicode
+// Evidenzia nel testo termini vietati, studenti nominati e dati personali trovati dal server
+function highlightTerms(content, matches, piiMatches, mentions) {
+    const marks = (matches || []).map(m => ({ ...m, cls: `term-${m.severity}`, title: m.pattern }))
+        .concat((mentions || []).map(m => ({ ...m, cls: 'mention', title: `Studente: ${m.first_name} ${m.last_name}` })))
+        .concat((piiMatches || []).map(m => ({ ...m, cls: 'pii', title: PII_TYPES[m.type] || m.type })));
+    if (marks.length === 0) return escapeHtml(content);
+    const chars = Array.from(content);
+    const sorted = marks.sort((a, b) => a.start - b.start);
+    let html = '';
+    let pos = 0;
+    sorted.forEach(m => {
+        if (m.start < pos) return; // sovrapposto a un'occorrenza già evidenziata
+        html += escapeHtml(chars.slice(pos, m.start).join(''));
+        html += `<mark class="${m.cls}" title="${escapeHtml(m.title)}">${escapeHtml(chars.slice(m.start, m.end).join(''))}</mark>`;
+        pos = m.end;
+    });
+    return html + escapeHtml(chars.slice(pos).join(''));
+}
+
+// Studenti reali nominati nel testo
+function mentionBadge(item) {
+    if (!item.mentioned_students) return '';
+    return `<span class="badge badge-danger">Nomina ${item.mentioned_students} ${item.mentioned_students === 1 ? 'studente' : 'studenti'}</span>`;
+}
+
+function mentionLinks(item) {
+    if (!item.mentions || item.mentions.length === 0) return '';
+    const users = new Map();
+    item.mentions.forEach(m => users.set(m.user_id, m));
+    const links = Array.from(users.values()).map(m =>
+        `<a href="#" onclick="showMentionedUser(event, '${escapeHtml(m.email)}')">${escapeHtml(m.first_name)} ${escapeHtml(m.last_name)}</a>`
+    ).join(', ');
+    return `<div class="mention-info"><strong>Studenti nominati:</strong> ${links}</div>`;
+}
+
+// Apre la sezione utenti cercando lo studente nominato
+function showMentionedUser(e, email) {
+    e.preventDefault();
+    document.querySelector('.nav-links a[data-section="users"]').click();
+    document.getElementById('user-search-input').value = email;
+    searchUsers();
+}
+
+// Quasi duplicati e invii in raffica
+const listDupGroups = {};
+
+function dupGroupBadge(item, listId, loader) {
+    if (!item.dup_group_id || item.dup_group_size < 2) return '';
+    const others = item.dup_group_size - 1;
+    const button = listDupGroups[listId]
+        ? ''
+        : ` <button class="btn btn-secondary btn-small" onclick="showDupGroup('${listId}', ${item.dup_group_id}, '${loader}')">Mostra gruppo</button>`;
+    return `<span class="badge badge-warning">${others} ${others === 1 ? 'simile' : 'simili'}</span>${button}`;
+}
+
+// Mostra solo il gruppo di quasi duplicati, da selezionare e trattare insieme
+function showDupGroup(listId, group, loader) {
+    listDupGroups[listId] = group;
+    const bar = document.getElementById(`${listId}-dup-filter`);
+    bar.innerHTML = `Gruppo di quasi duplicati #${group}
+        <button class="btn btn-secondary btn-small" onclick="clearDupGroup('${listId}', '${loader}')">Mostra tutti</button>`;
+    bar.classList.remove('hidden');
+    window[loader]();
+}
+
+function clearDupGroup(listId, loader) {
+    delete listDupGroups[listId];
+    document.getElementById(`${listId}-dup-filter`).classList.add('hidden');
+    window[loader]();
+}
+
+function burstBadge(item) {
+    if (!item.burst_flagged_at) return '';
+    return `<span class="badge badge-danger" title="Segnalato il ${formatDate(item.burst_flagged_at)}">Invio in raffica</span>`;
+}
+
+// Contenuti trattenuti perché l'autore è in shadow-ban
+function heldBadge(item) {
+    if (!item.held_at) return '';
+    return `<span class="badge badge-danger" title="Trattenuto dal ${formatDate(item.held_at)}">Trattenuto (shadow-ban)</span>`;
+}
+
+// Pubblicazione programmata
+const SCHEDULED_LOADERS = { '/posts': 'loadScheduledPosts', '/spotted': 'loadScheduledSpotted' };
+
+// toLocalInput formatta una data per un input datetime-local (ora locale)
+function toLocalInput(date) {
+    const pad = n => String(n).padStart(2, '0');
+    return `${date.getFullYear()}-${pad(date.getMonth() + 1)}-${pad(date.getDate())}T${pad(date.getHours())}:${pad(date.getMinutes())}`;
+}
+
+// openScheduleModal approva con pubblicazione programmata; con current
+// sposta la data di un elemento già programmato
+function openScheduleModal(path, id, loader, current = null) {
+    const input = document.getElementById('schedule-at');
+    const now = new Date();
+    input.min = toLocalInput(now);
+    input.value = toLocalInput(current ? new Date(current) : new Date(now.getTime() + 60 * 60 * 1000));
+    document.getElementById('schedule-title').textContent = current ? 'Sposta pubblicazione' : 'Programma pubblicazione';
+
+    document.getElementById('schedule-form').onsubmit = async (e) => {
+        e.preventDefault();
+        if (!input.value) return;
+        const body = { publish_at: new Date(input.value).toISOString() };
+        const data = current
+            ? await apiCall(`${path}/${id}/schedule`, 'PUT', body)
+            : await apiCall(`${path}/${id}/approve`, 'PUT', body);
+        if (data.status === 'error') {
+            alert(data.msg);
+            return;
+        }
+        closeScheduleModal();
+        await window[loader]();
+        if (loader !== SCHEDULED_LOADERS[path]) await window[SCHEDULED_LOADERS[path]]();
+    };
+    document.getElementById('schedule-modal').classList.remove('hidden');
+}
+
+function closeScheduleModal() {
+    document.getElementById('schedule-modal').classList.add('hidden');
+}
+
+async function cancelSchedule(path, id) {
+    if (!confirm('Annullare la pubblicazione programmata? L\'elemento tornerà in attesa.')) return;
+    const data = await apiCall(`${path}/${id}/schedule`, 'DELETE');
+    if (data.status === 'error') alert(data.msg);
+    await window[SCHEDULED_LOADERS[path]]();
+}
+
+async function publishNow(path, id) {
+    const data = await apiCall(`${path}/${id}/approve`, 'PUT');
+    if (data.status === 'error') alert(data.msg);
+    await window[SCHEDULED_LOADERS[path]]();
+}
+
+function scheduleBadge(item) {
+    if (!item.publish_at) return '';
+    const by = item.scheduled_by_name ? ` da ${escapeHtml(item.scheduled_by_name)}` : '';
+    return `<span class="badge badge-success" title="Programmato${by}">Pubblicazione ${formatDate(item.publish_at)}</span>`;
+}
+
+async function loadScheduled(path, listId, loader, append) {
+    try {
+        const data = await apiCall(`${path}/scheduled${listQuery(listId, append)}`);
+        const container = document.getElementById(listId);
+
+        if (!append && (!data.data || data.data.length === 0)) {
+            container.innerHTML = '<div class="empty-state"><p>Nessuna pubblicazione programmata</p></div>';
+            return;
+        }
+
+        setListHtml(container, append, data, loader, (data.data || []).map(item => `
+            <div class="card">
+                <div class="card-header">
+                    <div>
+                        ${creatorLink(item)}
+                        ${scheduleBadge(item)}
+                    </div>
+                </div>
+                <div class="card-meta">
+                    <span>${item.school_name || 'N/A'} - ${item.city_name || 'N/A'}</span>
+                    <span>${formatDate(item.creation_timestamp)}</span>
+                </div>
+                <div class="card-content">${escapeHtml(item.content)}</div>
+                <div class="card-actions">
+                    <button class="btn btn-success btn-small" onclick="publishNow('${path}', ${item.id})">Pubblica ora</button>
+                    <button class="btn btn-secondary btn-small" onclick="openScheduleModal('${path}', ${item.id}, '${loader}', '${item.publish_at}')">Sposta</button>
+                    <button class="btn btn-danger btn-small" onclick="cancelSchedule('${path}', ${item.id})">Annulla programmazione</button>
+                </div>
+            </div>
+        `).join(''));
+    } catch (err) {
+        console.error('Error loading scheduled:', err);
+    }
+}
+
+function loadScheduledPosts(append = false) {
+    return loadScheduled('/posts', 'scheduled-posts-list', 'loadScheduledPosts', append);
+}
+
+function loadScheduledSpotted(append = false) {
+    return loadScheduled('/spotted', 'scheduled-spotted-list', 'loadScheduledSpotted', append);
+}
+
+// Reinvii di contenuti già rifiutati
+function resubmissionBadge(item) {
+    if (item.resubmission_distance === null || item.resubmission_distance === undefined) return '';
+    return item.resubmission_distance === 0
+        ? '<span class="badge badge-danger">Reinvio identico</span>'
+        : '<span class="badge badge-danger">Reinvio simile</span>';
+}
+
+function resubmissionInfo(item) {
+    const r = item.resubmission;
+    if (!r) return '';
+    const by = r.moderator_name
+        ? escapeHtml(r.moderator_name)
+        : (r.actor_role === 'system' ? 'rifiuto automatico' : 'moderatore sconosciuto');
+    const reason = r.reason_label || r.reason;
+    return `<div class="resubmission-info">
+        <strong>${r.exact ? 'Stesso testo' : 'Testo quasi uguale'} di #${r.rejected_item_id}</strong>,
+        rifiutato il ${formatDate(r.rejected_at)} (${by})
+        ${reason ? `<br>Motivo: ${escapeHtml(reason)}` : ''}
+        ${r.note ? `<br>Nota: ${escapeHtml(r.note)}` : ''}
+    </div>`;
+}
+
+// Dati personali
+const PII_TYPES = {
+    phone: 'Telefono',
+    email: 'Email',
+    social_handle: 'Profilo social',
+    codice_fiscale: 'Codice fiscale',
+    address: 'Indirizzo'
+};
+
+function piiBadge(item) {
+    if (!item.pii_flagged_at) return '';
+    const types = (item.pii_flag_types || []).map(t => PII_TYPES[t] || t).join(', ');
+    return `<span class="badge badge-danger" title="Segnalato il ${formatDate(item.pii_flagged_at)}">Dati personali: ${escapeHtml(types)}</span>`;
+}
+
+// Banned terms
+const TERM_SEVERITIES = { low: 'Bassa', medium: 'Media', high: 'Alta' };
+const TERM_ACTIONS = { flag: 'Evidenzia', auto_reject: 'Rifiuto automatico' };
+
+async function loadBannedTerms() {
+    try {
+        const [data, reasons] = await Promise.all([apiCall('/banned-terms'), loadRejectionReasons()]);
+        const tbody = document.querySelector('#terms-table tbody');
+
+        document.getElementById('term-reason').innerHTML = '<option value="">Nessun motivo</option>' +
+            reasons.map(r => `<option value="${r.code}">${escapeHtml(r.label)}</option>`).join('');
+
+        if (!data.data || data.data.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="6" class="empty-state">Nessun termine vietato</td></tr>';
+            return;
+        }
+
+        tbody.innerHTML = data.data.map(t => `
+            <tr class="${t.active ? '' : 'term-inactive'}">
+                <td><code>${escapeHtml(t.pattern)}</code>${t.is_regex ? ' <span class="badge badge-warning">regex</span>' : ''}</td>
+                <td><span class="badge term-badge-${t.severity}">${TERM_SEVERITIES[t.severity]}</span></td>
+                <td>${TERM_ACTIONS[t.action]}${t.rejection_reason ? ` (${escapeHtml(t.rejection_reason)})` : ''}</td>
+                <td>${t.created_by_name ? escapeHtml(t.created_by_name) : '-'}</td>
+                <td>${t.active ? 'Attivo' : 'Disattivato'}</td>
+                <td class="actions">
+                    <button class="btn btn-secondary btn-small" onclick='toggleBannedTerm(${JSON.stringify(t).replace(/'/g, '&#39;')})'>${t.active ? 'Disattiva' : 'Attiva'}</button>
+                    <button class="btn btn-danger btn-small" onclick="confirmDeleteBannedTerm(${t.id})">Elimina</button>
+                </td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        console.error('Error loading banned terms:', err);
+    }
+}
+
+async function addBannedTerm(e) {
+    e.preventDefault();
+    const body = {
+        pattern: document.getElementById('term-pattern').value,
+        is_regex: document.getElementById('term-regex').checked,
+        severity: document.getElementById('term-severity').value,
+        action: document.getElementById('term-action').value,
+        rejection_reason: document.getElementById('term-reason').value || null
+    };
+
+    const data = await apiCall('/banned-terms', 'POST', body);
+    if (data.status !== 'ok') {
+        alert(data.msg || 'Errore nel salvataggio');
+        return;
+    }
+    document.getElementById('term-pattern').value = '';
+    await loadBannedTerms();
+}
+
+async function toggleBannedTerm(term) {
+    const data = await apiCall(`/banned-terms/${term.id}`, 'PUT', {
+        pattern: term.pattern,
+        is_regex: term.is_regex,
+        severity: term.severity,
+        action: term.action,
+        rejection_reason: term.rejection_reason,
+        active: !term.active
+    });
+    if (data.status !== 'ok') {
+        alert(data.msg || 'Errore nel salvataggio');
+        return;
+    }
+    await loadBannedTerms();
+}
+
+function confirmDeleteBannedTerm(id) {
+    document.getElementById('confirm-message').textContent = 'Sei sicuro di voler eliminare questo termine?';
+    document.getElementById('confirm-delete-btn').onclick = async () => {
+        await apiCall(`/banned-terms/${id}`, 'DELETE');
+        closeConfirmModal();
+        await loadBannedTerms();
+    };
+    document.getElementById('confirm-modal').classList.remove('hidden');
+}
+
+async function testBannedTerms() {
+    const text = document.getElementById('term-test-text').value;
+    const result = document.getElementById('term-test-result');
+    const data = await apiCall('/banned-terms/test', 'POST', { text });
+    if (data.status !== 'ok') {
+        result.innerHTML = `<p>${escapeHtml(data.msg)}</p>`;
+        return;
+    }
+    if (data.data.length === 0) {
+        result.innerHTML = '<p>Nessun termine vietato trovato</p>';
+        return;
+    }
+    result.innerHTML = `<div class="card-content">${highlightTerms(text, data.data)}</div>
+        <ul>${data.data.map(m => `<li><code>${escapeHtml(m.pattern)}</code> → "${escapeHtml(m.text)}" (${TERM_SEVERITIES[m.severity]}, ${TERM_ACTIONS[m.action]})</li>`).join('')}</ul>`;
+}
+
+async function searchContent(path, prefix) {
+    const q = document.getElementById(`${prefix}-input`).value.trim();
+    const container = document.getElementById(`${prefix}-list`);
+
+    if (q.length < 2) {
+        container.innerHTML = '<div class="empty-state"><p>Inserisci almeno 2 caratteri per cercare</p></div>';
+        return;
+    }
+
+    try {
+        const data = await apiCall(`${path}/search?q=${encodeURIComponent(q)}&page_size=100`);
+        if (!data.data || data.data.length === 0) {
+            container.innerHTML = '<div class="empty-state"><p>Nessun risultato</p></div>';
+            return;
+        }
+
+        container.innerHTML = `<p class="search-summary">${formatNumber(data.total)} risultati</p>` + data.data.map(item => `
+            <div class="card">
+                <div class="card-header">
+                    <div>
+                        <strong>${item.creator_first_name} ${item.creator_last_name}</strong>
+                        ${getStatusBadge(item.status)}
+                    </div>
+                </div>
+                <div class="card-meta">
+                    <span>${item.school_name || 'N/A'} - ${item.city_name || 'N/A'}</span>
+                    <span>${formatDate(item.creation_timestamp)}</span>
+                </div>
+                <div class="card-content">${highlightSnippet(item.snippet)}</div>
+            </div>
+        `).join('');
+    } catch (err) {
+        console.error('Error searching content:', err);
+        container.innerHTML = '<div class="empty-state"><p>Errore nella ricerca</p></div>';
+    }
+}
+
 function closeConfirmModal() {
     document.getElementById('confirm-modal').classList.add('hidden');
 }
@@ -714,6 +1606,9 @@ async function loadStatistics() {
         renderTemporalStats(data.data.posts_over_time, 'posts-time-table');
         renderTemporalStats(data.data.spotted_over_time, 'spotted-time-table');
 
+        // Render rejection reasons
+        renderRejectionStats(data.data.rejection_reasons);
+
     } catch (err) {
         console.error('Error loading statistics:', err);
     }
@@ -842,6 +1737,23 @@ function renderTemporalStats(data, tableId) {
     }).join('');
 }
 
+function renderRejectionStats(reasons) {
+    const tbody = document.getElementById('rejections-stats-table');
+    if (!reasons || reasons.length === 0) {
+        tbody.innerHTML = '<tr><td colspan="4" class="empty-state">Nessun contenuto rifiutato</td></tr>';
+        return;
+    }
+
+    tbody.innerHTML = reasons.map(r => `
+        <tr>
+            <td>${escapeHtml(r.label)}</td>
+            <td>${formatNumber(r.post_count)}</td>
+            <td>${formatNumber(r.spotted_count)}</td>
+            <td><strong>${formatNumber(r.post_count + r.spotted_count)}</strong></td>
+        </tr>
+    `).join('');
+}
+
 function formatMonth(monthStr) {
     if (!monthStr) return '-';
     const [year, month] = monthStr.split('-');
@@ -913,9 +1825,11 @@ async function searchUsers() {
                         ${user.personal_email ? `<div style="color:#888;font-size:0.85rem">${user.personal_email}</div>` : ''}
                     </td>
                     <td>${user.school_name || 'N/A'}${user.city_name ? ` (${user.city_name})` : ''}</td>
-                    <td><span class="badge ${roleBadgeClass}">${roleLabel}</span></td>
+                    <td><span class="badge ${roleBadgeClass}">${roleLabel}</span> ${sanctionBadges(user)}</td>
                     <td class="actions">
                         <button class="btn ${buttonClass} btn-small" onclick="toggleUserRole(${user.id}, '${user.role}')">${buttonText}</button>
+                        <button class="btn btn-secondary btn-small" onclick="openSanctionsModal(${user.id})">Sanzioni</button>
+                        ${userRole === 'users_only' ? '' : `<button class="btn btn-secondary btn-small" onclick="openProfileModal(event, ${user.id})">Profilo</button>`}
                     </td>
                 </tr>
             `;
@@ -931,6 +1845,197 @@ async function searchUsers() {
     }
 }
 
+// Profilo di moderazione dell'autore
+function creatorLink(item) {
+    const name = `${escapeHtml(item.creator_first_name)} ${escapeHtml(item.creator_last_name)}`;
+    return `<strong><a href="#" class="creator-link" title="Profilo dell'autore" onclick="openProfileModal(event, ${item.creator_id})">${name}</a></strong>`;
+}
+
+function profileStatsRow(label, s) {
+    return `
+        <tr>
+            <td>${label}</td>
+            <td>${s.total}</td>
+            <td>${s.pending}${s.held ? ` (${s.held} trattenuti)` : ''}</td>
+            <td>${s.approved}</td>
+            <td>${s.rejected}</td>
+            <td>${s.deleted}</td>
+            <td>${s.likes_received}</td>
+            <td>${s.reports_received} (${s.open_reports} aperte)</td>
+            <td>${s.reports_filed}</td>
+        </tr>
+    `;
+}
+
+function renderProfile(p) {
+    const kinds = Object.keys(p.by_kind).sort();
+    const rate = p.rejection_rate === null ? 'n/d' : `${Math.round(p.rejection_rate * 100)}%`;
+    const age = p.account_age_days === null ? 'n/d' : `${p.account_age_days} giorni`;
+    const roles = p.role_history.length === 0
+        ? '<p>Nessun cambio di ruolo</p>'
+        : p.role_history.map(r => `<div class="revision-reason">${formatDate(r.changed_at)}: ${escapeHtml(r.from || '?')} → ${escapeHtml(r.to || '?')} (${r.moderator_name ? escapeHtml(r.moderator_name) : r.actor_role})</div>`).join('');
+    const sanctions = p.active_sanctions.length === 0
+        ? '<p>Nessuna sanzione attiva</p>'
+        : p.active_sanctions.map(s => renderSanction(p.id, s, false)).join('');
+    const items = p.latest_items.length === 0
+        ? '<p>Nessun contenuto</p>'
+        : p.latest_items.map(i => `
+            <div class="revision">
+                <div class="card-meta">
+                    <span><strong>${i.kind} #${i.id}</strong> <span class="badge">${i.status}</span> ${i.report_count ? `<span class="badge badge-danger">${i.report_count} segnalazioni</span>` : ''}</span>
+                    <span>${formatDate(i.creation_timestamp)}</span>
+                </div>
+                <div class="revision-diff">${escapeHtml(i.content || '')}</div>
+            </div>
+        `).join('');
+    return `
+        <p><strong>${escapeHtml(p.first_name)} ${escapeHtml(p.last_name)}</strong> — ${escapeHtml(p.email)}
+            ${p.school_name ? ` — ${escapeHtml(p.school_name)}` : ''} ${sanctionBadges(p)}</p>
+        <p>Iscritto da: ${age} — Tasso di rifiuto: <strong>${rate}</strong></p>
+        <table>
+            <thead>
+                <tr><th>Tipo</th><th>Totale</th><th>In attesa</th><th>Approvati</th><th>Rifiutati</th><th>Eliminati</th><th>Like</th><th>Segnalazioni ricevute</th><th>Segnalazioni inviate</th></tr>
+            </thead>
+            <tbody>
+                ${kinds.map(k => profileStatsRow(k, p.by_kind[k])).join('')}
+                ${profileStatsRow('Totale', p.totals)}
+            </tbody>
+        </table>
+        <h4>Sanzioni attive</h4>
+        ${sanctions}
+        <h4>Cambi di ruolo</h4>
+        ${roles}
+        <h4>Ultimi contenuti</h4>
+        ${items}
+    `;
+}
+
+async function openProfileModal(e, userId) {
+    e.preventDefault();
+    const body = document.getElementById('profile-body');
+    body.innerHTML = '<p>Caricamento...</p>';
+    document.getElementById('profile-modal').classList.remove('hidden');
+    try {
+        const data = await apiCall(`/users/${userId}/profile`);
+        body.innerHTML = data.status === 'ok' ? renderProfile(data.data) : `<p>${escapeHtml(data.msg)}</p>`;
+    } catch (err) {
+        body.innerHTML = '<p>Errore nel caricamento del profilo</p>';
+    }
+}
+
+function closeProfileModal() {
+    document.getElementById('profile-modal').classList.add('hidden');
+}
+
+// Sanzioni
+const SANCTION_TYPES = {
+    warning: 'Avvertimento',
+    suspension: 'Sospensione',
+    ban: 'Ban',
+    shadow_ban: 'Shadow-ban'
+};
+
+function sanctionBadges(state) {
+    const badges = [];
+    if (state.banned_at) badges.push('<span class="badge badge-danger">Bannato</span>');
+    if (state.suspended_until) badges.push(`<span class="badge badge-danger">Sospeso fino al ${formatDate(state.suspended_until)}</span>`);
+    if (state.shadow_banned_at) badges.push('<span class="badge badge-warning">Shadow-ban</span>');
+    return badges.join(' ');
+}
+
+function renderSanction(userId, s, actions = true) {
+    let status;
+    if (s.type === 'warning') {
+        status = '';
+    } else if (s.active) {
+        status = '<span class="badge badge-danger">Attiva</span>';
+    } else if (s.lift_reason === 'expired') {
+        status = `<span class="badge">Scaduta il ${formatDate(s.lifted_at)}</span>`;
+    } else {
+        status = `<span class="badge">Revocata il ${formatDate(s.lifted_at)}${s.lifted_by_name ? ' da ' + escapeHtml(s.lifted_by_name) : ''}</span>`;
+    }
+    return `
+        <div class="revision">
+            <div class="card-meta">
+                <span><strong>${SANCTION_TYPES[s.type] || s.type}</strong> ${status}</span>
+                <span>${formatDate(s.created_at)} — ${s.moderator_name ? escapeHtml(s.moderator_name) : 'gestione utenti'}</span>
+            </div>
+            <div class="revision-reason">${escapeHtml(s.reason)}</div>
+            ${s.expires_at ? `<div class="revision-reason">Scadenza: ${formatDate(s.expires_at)}</div>` : ''}
+            ${s.lift_reason && s.lift_reason !== 'expired' ? `<div class="revision-reason">Motivo revoca: ${escapeHtml(s.lift_reason)}</div>` : ''}
+            ${s.active && actions ? `<button class="btn btn-secondary btn-small" onclick="liftSanction(${userId}, ${s.id})">Revoca</button>` : ''}
+        </div>
+    `;
+}
+
+async function loadSanctions(userId) {
+    const history = document.getElementById('sanctions-history');
+    const data = await apiCall(`/users/${userId}/sanctions`);
+    if (data.status !== 'ok') {
+        history.innerHTML = `<p>${escapeHtml(data.msg)}</p>`;
+        return;
+    }
+    document.getElementById('sanctions-state').innerHTML = sanctionBadges(data.data) || '<p>Nessuna sanzione attiva</p>';
+    history.innerHTML = data.data.sanctions.length === 0
+        ? '<p>Nessuna sanzione</p>'
+        : data.data.sanctions.map(s => renderSanction(userId, s)).join('');
+}
+
+async function openSanctionsModal(userId) {
+    document.getElementById('sanctions-title').textContent = `Sanzioni utente #${userId}`;
+    document.getElementById('sanction-form').reset();
+    document.getElementById('sanction-reject-label').classList.toggle('hidden', userRole === 'users_only');
+    document.getElementById('sanctions-state').innerHTML = '';
+    document.getElementById('sanctions-history').innerHTML = '<p>Caricamento...</p>';
+
+    document.getElementById('sanction-form').onsubmit = async (e) => {
+        e.preventDefault();
+        const type = document.getElementById('sanction-type').value;
+        const hours = parseInt(document.getElementById('sanction-hours').value);
+        const body = {
+            type,
+            reason: document.getElementById('sanction-reason').value.trim(),
+            reject_pending: document.getElementById('sanction-reject-pending').checked
+        };
+        if (hours && (type === 'suspension' || type === 'shadow_ban')) body.duration_hours = hours;
+        const data = await apiCall(`/users/${userId}/sanctions`, 'POST', body);
+        if (data.status !== 'ok') {
+            alert(data.msg);
+            return;
+        }
+        if (body.reject_pending) {
+            const failed = (data.data.reject_failures || []).length;
+            alert(`Contenuti in attesa rifiutati: ${data.data.rejected_pending}${failed ? `, non rifiutati: ${failed}` : ''}`);
+        }
+        document.getElementById('sanction-form').reset();
+        await loadSanctions(userId);
+        await searchUsers();
+    };
+    document.getElementById('sanctions-modal').classList.remove('hidden');
+
+    try {
+        await loadSanctions(userId);
+    } catch (err) {
+        document.getElementById('sanctions-history').innerHTML = '<p>Errore nel caricamento delle sanzioni</p>';
+    }
+}
+
+async function liftSanction(userId, sanctionId) {
+    const reason = prompt('Motivo della revoca (facoltativo):');
+    if (reason === null) return;
+    const data = await apiCall(`/users/${userId}/sanctions/${sanctionId}`, 'DELETE', { reason: reason.trim() });
+    if (data.status !== 'ok') {
+        alert(data.msg);
+        return;
+    }
+    await loadSanctions(userId);
+    await searchUsers();
+}
+
+function closeSanctionsModal() {
+    document.getElementById('sanctions-modal').classList.add('hidden');
+}
+
 async function toggleUserRole(userId, currentRole) {
     const newRole = currentRole === 'representative' ? 'user' : 'representative';
     const action = newRole === 'representative' ? 'rendere Rappresentante' : 'rimuovere il ruolo Rappresentante a';
@@ -947,7 +2052,223 @@ async function toggleUserRole(userId, currentRole) {
     }
 }
 
+// Audit
+const AUDIT_ACTIONS = {
+    'approve': 'Approvazione',
+    'reject': 'Rifiuto',
+    'set_status': 'Cambio stato',
+    'delete': 'Eliminazione',
+    'dismiss_reports': 'Archiviazione segnalazioni',
+    'restore': 'Ripristino',
+    'purge': 'Eliminazione definitiva',
+    'auto_reject': 'Rifiuto automatico',
+    'auto_hide': 'Occultamento automatico',
+    'steal_claim': 'Presa in carico forzata',
+    'release_claim': 'Rilascio forzato',
+    'edit_content': 'Modifica testo',
+    'revert_content': 'Ripristino testo',
+    'pii_flag': 'Segnalazione dati personali',
+    'mention_flag': 'Studenti nominati',
+    'resubmission_flag': 'Reinvio di contenuto rifiutato',
+    'auto_reject_resubmission': 'Rifiuto automatico reinvio',
+    'set_role': 'Cambio ruolo',
+    'add_sanction': 'Sanzione',
+    'lift_sanction': 'Revoca sanzione',
+    'schedule_publish': 'Pubblicazione programmata',
+    'reschedule_publish': 'Pubblicazione spostata',
+    'cancel_schedule': 'Programmazione annullata',
+    'scheduled_publish': 'Pubblicazione automatica'
+};
+
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function formatAuditValue(value) {
+    if (value === null || value === undefined) return '-';
+    return `<div class="audit-value">${escapeHtml(JSON.stringify(value, null, 1))}</div>`;
+}
+
+async function loadAudit(page = 1) {
+    const params = new URLSearchParams({ page, page_size: 50 });
+    const filters = {
+        action: document.getElementById('audit-action-filter').value,
+        target_type: document.getElementById('audit-target-filter').value,
+        target_id: document.getElementById('audit-target-id').value,
+        from: document.getElementById('audit-from').value,
+        to: document.getElementById('audit-to').value
+    };
+    Object.entries(filters).forEach(([key, value]) => {
+        if (value) params.set(key, value);
+    });
+
+    try {
+        const data = await apiCall(`/audit?${params}`);
+        const tbody = document.querySelector('#audit-table tbody');
+        const pagination = document.getElementById('audit-pagination');
+
+        if (!data.data || data.data.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="7" class="empty-state">Nessuna azione registrata</td></tr>';
+            pagination.innerHTML = '';
+            return;
+        }
+
+        tbody.innerHTML = data.data.map(e => `
+            <tr>
+                <td>${formatDate(e.created_at)}</td>
+                <td>${e.actor_name || e.actor_role}</td>
+                <td>${AUDIT_ACTIONS[e.action] || e.action}</td>
+                <td>${e.target_type} #${e.target_id}</td>
+                <td>${formatAuditValue(e.before)}</td>
+                <td>${formatAuditValue(e.after)}</td>
+                <td>${e.ip || '-'}</td>
+            </tr>
+        `).join('');
+
+        const pages = Math.max(1, Math.ceil(data.total / data.page_size));
+        pagination.innerHTML = `
+            <button class="btn btn-secondary btn-small" ${data.page <= 1 ? 'disabled' : ''} onclick="loadAudit(${data.page - 1})">&laquo;</button>
+            <span>Pagina ${data.page} di ${pages} (${formatNumber(data.total)} azioni)</span>
+            <button class="btn btn-secondary btn-small" ${data.page >= pages ? 'disabled' : ''} onclick="loadAudit(${data.page + 1})">&raquo;</button>
+        `;
+    } catch (err) {
+        console.error('Error loading audit log:', err);
+    }
+}
+
 // Expose functions to global scope for onclick handlers
+// Aggiornamenti in tempo reale (Server-Sent Events). EventSource non
+// permette l'header Authorization, quindi lo stream si legge con fetch.
+const EVENT_LISTS = {
+    post: { pending: ['pending-posts-list', 'loadPendingPosts'], reported: ['reported-posts-list', 'loadReportedPosts'] },
+    spotted: { pending: ['pending-spotted-list', 'loadPendingSpotted'], reported: ['reported-spotted-list', 'loadReportedSpotted'] },
+};
+let eventStreamAbort = null;
+let lastEventId = null;
+const eventReloadTimers = {};
+
+function startEventStream() {
+    stopEventStream();
+    const controller = new AbortController();
+    eventStreamAbort = controller;
+    let retryDelay = 5000;
+
+    const connect = async () => {
+        while (!controller.signal.aborted && token) {
+            try {
+                const headers = { 'Authorization': `Bearer ${token}` };
+                if (lastEventId) headers['Last-Event-ID'] = lastEventId;
+                const response = await fetch(`${API_BASE}/events`, { headers, signal: controller.signal });
+                if (response.status === 401) {
+                    logout();
+                    return;
+                }
+                if (!response.ok || !response.body) throw new Error(`HTTP ${response.status}`);
+                retryDelay = 5000;
+                await readEventStream(response.body, controller.signal, ms => { retryDelay = ms; });
+            } catch (err) {
+                if (controller.signal.aborted) return;
+                console.error('Event stream error:', err);
+            }
+            await new Promise(resolve => setTimeout(resolve, retryDelay));
+        }
+    };
+    connect();
+}
+
+function stopEventStream() {
+    if (eventStreamAbort) eventStreamAbort.abort();
+    eventStreamAbort = null;
+}
+
+async function readEventStream(body, signal, setRetry) {
+    const reader = body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    let event = { type: 'message', data: [] };
+
+    while (!signal.aborted) {
+        const { value, done } = await reader.read();
+        if (done) return;
+        buffer += decoder.decode(value, { stream: true });
+
+        let newline;
+        while ((newline = buffer.search(/\r?\n/)) >= 0) {
+            const line = buffer.slice(0, newline);
+            buffer = buffer.slice(newline + (buffer[newline] === '\r' ? 2 : 1));
+
+            if (line === '') {
+                if (event.data.length > 0) handleModerationEvent(event.type, event.data.join('\n'));
+                event = { type: 'message', data: [] };
+                continue;
+            }
+            if (line.startsWith(':')) continue;
+
+            const colon = line.indexOf(':');
+            const field = colon >= 0 ? line.slice(0, colon) : line;
+            const val = colon >= 0 ? line.slice(colon + 1).replace(/^ /, '') : '';
+            if (field === 'event') event.type = val;
+            else if (field === 'data') event.data.push(val);
+            else if (field === 'id') lastEventId = val;
+            else if (field === 'retry' && /^\d+$/.test(val)) setRetry(parseInt(val));
+        }
+    }
+}
+
+// Ricarica una lista solo se è visibile e nessun elemento è selezionato,
+// raggruppando gli eventi ravvicinati
+function scheduleListReload(listId, loader) {
+    const container = document.getElementById(listId);
+    if (!container || container.closest('.section').classList.contains('hidden')) return;
+    clearTimeout(eventReloadTimers[listId]);
+    eventReloadTimers[listId] = setTimeout(() => {
+        if (selectedIds(listId).length > 0) return;
+        window[loader]();
+    }, 1000);
+}
+
+// Toglie dalle liste una card gestita da un altro moderatore
+function removeHandledCard(listId, itemId) {
+    document.querySelectorAll(`#${listId} .bulk-select[value="${itemId}"]`).forEach(cb => {
+        const card = cb.closest('.card');
+        if (card) card.remove();
+    });
+    updateSelectedCount(listId);
+}
+
+function handleModerationEvent(type, data) {
+    if (type === 'resync') {
+        Object.values(EVENT_LISTS).forEach(lists => Object.values(lists).forEach(([listId, loader]) => scheduleListReload(listId, loader)));
+        return;
+    }
+
+    let ev;
+    try {
+        ev = JSON.parse(data);
+    } catch {
+        return;
+    }
+    const lists = EVENT_LISTS[ev.kind];
+    if (!lists) return;
+
+    switch (type) {
+        case 'pending_new':
+            scheduleListReload(...lists.pending);
+            break;
+        case 'report_new':
+            scheduleListReload(...lists.reported);
+            break;
+        case 'status_changed':
+            // Un contenuto tornato in attesa (es. nascosto automaticamente) rientra in coda
+            if (ev.status === 'received') scheduleListReload(...lists.pending);
+            else removeHandledCard(lists.pending[0], ev.item_id);
+            if (ev.status !== 'approved') removeHandledCard(lists.reported[0], ev.item_id);
+            break;
+    }
+}
+
 window.searchUsers = searchUsers;
 window.handleUserSearchKeyup = handleUserSearchKeyup;
 window.toggleUserRole = toggleUserRole;
@@ -971,6 +2292,36 @@ window.setSpottedStatus = setSpottedStatus;
 window.closeConfirmModal = closeConfirmModal;
 window.loadStatistics = loadStatistics;
 window.filterSchoolsStats = filterSchoolsStats;
+window.loadAudit = loadAudit;
+window.searchContent = searchContent;
+window.toggleSelectAll = toggleSelectAll;
+window.bulkAction = bulkAction;
+window.closeRejectModal = closeRejectModal;
+window.showReports = showReports;
+window.dismissReports = dismissReports;
+window.loadTrash = loadTrash;
+window.restoreContent = restoreContent;
+window.closeReportsModal = closeReportsModal;
+window.openEditModal = openEditModal;
+window.revertContent = revertContent;
+window.closeEditModal = closeEditModal;
+window.showMentionedUser = showMentionedUser;
+window.claimItem = claimItem;
+window.releaseClaim = releaseClaim;
+window.claimNext = claimNext;
+window.renderPendingPost = renderPendingPost;
+window.renderPendingSpotted = renderPendingSpotted;
+window.openScheduleModal = openScheduleModal;
+window.closeScheduleModal = closeScheduleModal;
+window.cancelSchedule = cancelSchedule;
+window.publishNow = publishNow;
+window.loadScheduledPosts = loadScheduledPosts;
+window.loadScheduledSpotted = loadScheduledSpotted;
+window.loadPendingPosts = loadPendingPosts;
+window.loadPendingSpotted = loadPendingSpotted;
+window.toggleBannedTerm = toggleBannedTerm;
+window.confirmDeleteBannedTerm = confirmDeleteBannedTerm;
+window.testBannedTerms = testBannedTerms;
 
 // Initialize
 document.addEventListener('DOMContentLoaded', async () => {
@@ -978,6 +2329,7 @@ document.addEventListener('DOMContentLoaded', async () => {
     document.getElementById('logout-btn').addEventListener('click', logout);
     document.getElementById('city-form').addEventListener('submit', saveCity);
     document.getElementById('school-form').addEventListener('submit', saveSchool);
+    document.getElementById('term-form').addEventListener('submit', addBannedTerm);
 
     setupNavigation();
     setupStatsTabs();
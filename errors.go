@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNotFound viene restituito dal livello query quando un UPDATE/DELETE
+// non tocca nessuna riga (ID inesistente).
+var ErrNotFound = errors.New("record not found")
+
+// Codici SQLSTATE di Postgres gestiti esplicitamente
+const (
+	PG_UNIQUE_VIOLATION      = "23505"
+	PG_FOREIGN_KEY_VIOLATION = "23503"
+	PG_NOT_NULL_VIOLATION    = "23502"
+	PG_CHECK_VIOLATION       = "23514"
+	PG_INVALID_TEXT_REPR     = "22P02"
+)
+
+// checkAffected trasforma un comando che non ha toccato righe in ErrNotFound.
+// Si usa direttamente sul risultato di Exec: checkAffected(db.Exec(...)).
+func checkAffected(tag pgconn.CommandTag, err error) error {
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// classifyDbError mappa un errore del database su status HTTP, codice
+// leggibile dalle macchine e constraint coinvolto (se presente).
+func classifyDbError(err error) (int, string, string) {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound, "not_found", ""
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case PG_UNIQUE_VIOLATION:
+			return http.StatusConflict, "unique_violation", pgErr.ConstraintName
+		case PG_FOREIGN_KEY_VIOLATION:
+			return http.StatusConflict, "foreign_key_violation", pgErr.ConstraintName
+		case PG_NOT_NULL_VIOLATION:
+			return http.StatusUnprocessableEntity, "not_null_violation", pgErr.ColumnName
+		case PG_CHECK_VIOLATION:
+			return http.StatusUnprocessableEntity, "check_violation", pgErr.ConstraintName
+		case PG_INVALID_TEXT_REPR:
+			return http.StatusUnprocessableEntity, "invalid_value", ""
+		}
+	}
+
+	return http.StatusInternalServerError, "", ""
+}
+
+// respondDbError scrive la risposta d'errore per un errore del livello query.
+// entity è il nome della risorsa (es. "city") usato per i codici 404;
+// fallbackCode/fallbackMsg valgono per gli errori non classificati.
+func respondDbError(ctx *gin.Context, err error, entity, fallbackCode, fallbackMsg string) {
+	status, code, constraint := classifyDbError(err)
+
+	switch status {
+	case http.StatusNotFound:
+		ctx.JSON(status, ErrorResponse{
+			Status: "error",
+			Error:  entity + "_not_found",
+			Msg:    capitalize(entity) + " not found",
+		})
+	case http.StatusConflict:
+		msg := capitalize(entity) + " conflicts with existing data"
+		if code == "foreign_key_violation" {
+			msg = capitalize(entity) + " is still referenced by, or references, other records"
+		}
+		ctx.JSON(status, ErrorResponse{
+			Status:     "error",
+			Error:      code,
+			Msg:        msg,
+			Constraint: constraint,
+		})
+	case http.StatusUnprocessableEntity:
+		ctx.JSON(status, ErrorResponse{
+			Status:     "error",
+			Error:      code,
+			Msg:        "Invalid value for " + entity,
+			Constraint: constraint,
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  fallbackCode,
+			Msg:    fallbackMsg,
+		})
+	}
+}
+
+// capitalize rende leggibile il nome di un'entità: "rejection_reason" → "Rejection reason"
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, "_", " ")
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassifyDbError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		constraint string
+	}{
+		{"not found", ErrNotFound, http.StatusNotFound, "not_found", ""},
+		{"nessuna riga", pgx.ErrNoRows, http.StatusNotFound, "not_found", ""},
+		{"not found avvolto", fmt.Errorf("update: %w", ErrNotFound), http.StatusNotFound, "not_found", ""},
+		{"unique", &pgconn.PgError{Code: PG_UNIQUE_VIOLATION, ConstraintName: "cities_name_key"}, http.StatusConflict, "unique_violation", "cities_name_key"},
+		{"foreign key", &pgconn.PgError{Code: PG_FOREIGN_KEY_VIOLATION, ConstraintName: "schools_city_fkey"}, http.StatusConflict, "foreign_key_violation", "schools_city_fkey"},
+		{"not null", &pgconn.PgError{Code: PG_NOT_NULL_VIOLATION, ColumnName: "name"}, http.StatusUnprocessableEntity, "not_null_violation", "name"},
+		{"check", &pgconn.PgError{Code: PG_CHECK_VIOLATION, ConstraintName: "post_status_check"}, http.StatusUnprocessableEntity, "check_violation", "post_status_check"},
+		{"testo non valido", &pgconn.PgError{Code: PG_INVALID_TEXT_REPR}, http.StatusUnprocessableEntity, "invalid_value", ""},
+		{"pg avvolto", fmt.Errorf("insert: %w", &pgconn.PgError{Code: PG_UNIQUE_VIOLATION, ConstraintName: "k"}), http.StatusConflict, "unique_violation", "k"},
+		{"altro codice pg", &pgconn.PgError{Code: "40001"}, http.StatusInternalServerError, "", ""},
+		{"errore generico", errors.New("connection reset"), http.StatusInternalServerError, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code, constraint := classifyDbError(tt.err)
+			if status != tt.status || code != tt.code || constraint != tt.constraint {
+				t.Errorf("classifyDbError(%v) = (%d, %q, %q), want (%d, %q, %q)",
+					tt.err, status, code, constraint, tt.status, tt.code, tt.constraint)
+			}
+		})
+	}
+}
+
+func TestCheckAffected(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name string
+		tag  pgconn.CommandTag
+		err  error
+		want error
+	}{
+		{"una riga", pgconn.NewCommandTag("UPDATE 1"), nil, nil},
+		{"nessuna riga", pgconn.NewCommandTag("UPDATE 0"), nil, ErrNotFound},
+		{"errore", pgconn.CommandTag{}, boom, boom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkAffected(tt.tag, tt.err); !errors.Is(got, tt.want) || (tt.want == nil && got != nil) {
+				t.Errorf("checkAffected = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
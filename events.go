@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	EVENT_CHANNEL         = "moderation_events"
+	EVENT_RETENTION       = 24 * time.Hour
+	EVENT_POLL_INTERVAL   = time.Minute // anche senza NOTIFY si controlla periodicamente
+	EVENT_RECONNECT_DELAY = 5 * time.Second
+	EVENT_REPLAY_LIMIT    = 500
+	EVENT_BUFFER          = 64
+	EVENT_HEARTBEAT       = 25 * time.Second
+	// Gli eventi sono scritti dai trigger dentro le transazioni: uno con ID
+	// più basso può diventare visibile dopo uno più alto. A ogni lettura si
+	// ripassano gli ultimi EVENT_LOOKBACK ID, saltando quelli già inoltrati.
+	EVENT_LOOKBACK = 200
+)
+
+var validEventTypes = map[string]bool{"pending_new": true, "report_new": true, "status_changed": true}
+
+// eventHub tiene una connessione in LISTEN e inoltra gli eventi a tutti gli
+// stream SSE aperti. Un client troppo lento viene disconnesso (il canale si
+// chiude): riconnettendosi con Last-Event-ID recupera quanto perso.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan ModerationEvent]bool
+	lastId      int64
+	// ID già inoltrati nella finestra [lastId-EVENT_LOOKBACK, lastId]
+	sent map[int64]bool
+}
+
+var EVENT_HUB = &eventHub{subscribers: map[chan ModerationEvent]bool{}, sent: map[int64]bool{}}
+
+func (h *eventHub) subscribe() chan ModerationEvent {
+	ch := make(chan ModerationEvent, EVENT_BUFFER)
+	h.mu.Lock()
+	h.subscribers[ch] = true
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan ModerationEvent) {
+	h.mu.Lock()
+	if h.subscribers[ch] {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+	h.mu.Unlock()
+}
+
+func (h *eventHub) broadcast(ev ModerationEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// fetchNew legge gli eventi non ancora inoltrati, compresi quelli comparsi
+// in ritardo nella finestra EVENT_LOOKBACK, e li distribuisce. Con
+// deliver=false li segna solo come inoltrati (primo avvio).
+func (h *eventHub) fetchNew(db *pgx.Conn, deliver bool) error {
+	after := max(h.lastId-EVENT_LOOKBACK, 0)
+	for {
+		events, err := QueryEventsAfter(db, after, EVENT_REPLAY_LIMIT)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			after = ev.ID
+			if h.sent[ev.ID] {
+				continue
+			}
+			if deliver {
+				h.broadcast(ev)
+			}
+			h.sent[ev.ID] = true
+			h.lastId = max(h.lastId, ev.ID)
+		}
+		if len(events) < EVENT_REPLAY_LIMIT {
+			break
+		}
+	}
+	for id := range h.sent {
+		if id < h.lastId-EVENT_LOOKBACK {
+			delete(h.sent, id)
+		}
+	}
+	return nil
+}
+
+// listen resta in ascolto finché la connessione funziona
+func (h *eventHub) listen() error {
+	db, err := makeDbaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close(context.Background())
+
+	if _, err := db.Exec(context.Background(), "LISTEN "+EVENT_CHANNEL); err != nil {
+		return err
+	}
+
+	// Al primo avvio si parte dall'ultimo evento, segnando come inoltrati
+	// quelli della finestra; dopo una riconnessione si recuperano quelli
+	// persi nel frattempo
+	if h.lastId == 0 {
+		if h.lastId, err = QueryLatestEventId(db); err != nil {
+			return err
+		}
+		if err := h.fetchNew(db, false); err != nil {
+			return err
+		}
+	}
+	if err := h.fetchNew(db, true); err != nil {
+		return err
+	}
+
+	lastPurge := time.Time{}
+	for {
+		if time.Since(lastPurge) > time.Hour {
+			if err := PurgeModerationEvents(db, EVENT_RETENTION); err != nil {
+				return err
+			}
+			lastPurge = time.Now()
+		}
+
+		waitCtx, cancel := context.WithTimeout(context.Background(), EVENT_POLL_INTERVAL)
+		_, err := db.WaitForNotification(waitCtx)
+		cancel()
+		if err != nil && !pgconn.Timeout(err) {
+			return err
+		}
+
+		// Il payload è l'ID dell'evento: si leggono tutti quelli nuovi in una volta
+		if err := h.fetchNew(db, true); err != nil {
+			return err
+		}
+	}
+}
+
+// start avvia in background l'ascolto, riconnettendosi in caso di errore
+func (h *eventHub) start() {
+	go func() {
+		for {
+			err := h.listen()
+			println("Event stream error:", err.Error())
+			time.Sleep(EVENT_RECONNECT_DELAY)
+		}
+	}()
+}
+
+// eventFilter è l'ambito di uno stream: tipi di contenuto, tipi di evento e
+// città/scuola richiesti. Le azioni del moderatore stesso non vengono inviate.
+type eventFilter struct {
+	Kinds       map[string]bool
+	Types       map[string]bool
+	CityID      *int
+	SchoolID    *int
+	ModeratorID int
+}
+
+func (f eventFilter) match(ev ModerationEvent) bool {
+	if len(f.Kinds) > 0 && !f.Kinds[ev.Kind] {
+		return false
+	}
+	if len(f.Types) > 0 && !f.Types[ev.Type] {
+		return false
+	}
+	if f.CityID != nil && (ev.CityID == nil || *ev.CityID != *f.CityID) {
+		return false
+	}
+	if f.SchoolID != nil && (ev.SchoolID == nil || *ev.SchoolID != *f.SchoolID) {
+		return false
+	}
+	if ev.ActorID != nil && *ev.ActorID == f.ModeratorID {
+		return false
+	}
+	return true
+}
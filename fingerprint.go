@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	FINGERPRINT_INTERVAL = time.Minute
+	FINGERPRINT_BATCH    = 500
+
+	// Massima distanza di Hamming tra due simhash perché due testi siano
+	// considerati quasi uguali
+	SIMHASH_MAX_DISTANCE = 6
+
+	// Bande in cui si divide il simhash per trovare i candidati: con una
+	// distanza massima minore del numero di bande, due impronte vicine hanno
+	// almeno una banda identica
+	SIMHASH_BANDS = 8
+
+	DEFAULT_SIMILAR_LIMIT = 20
+	MAX_SIMILAR_LIMIT     = 100
+)
+
+// Distanza di Hamming tra una colonna simhash e un parametro, da comporre
+// con fmt.Sprintf (bit_count richiederebbe PostgreSQL 14)
+const SIMHASH_DISTANCE_EXPR = `length(replace((%s # %s)::bit(64)::text, '0', ''))`
+
+// normalizedWords riduce il testo a parole confrontabili con la stessa
+// normalizzazione dei termini vietati: "C14O   ciaooo" e "ciao ciao" coincidono
+func normalizedWords(text string) []string {
+	var words []string
+	var word []rune
+	for _, n := range normalizeText(text) {
+		if n.wordStart && len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+		word = append(word, n.r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// simhash calcola l'impronta a 64 bit del testo a partire dalle singole
+// parole normalizzate: testi simili hanno impronte che differiscono in pochi
+// bit. Sui testi brevi gli shingle di più parole amplificano le piccole
+// differenze (una parola cambiata ne altera diversi), per questo non si usano.
+// Un testo senza parole ha impronta 0, che non viene confrontata.
+func simhash(text string) int64 {
+	words := normalizedWords(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, word := range words {
+		h := fnv.New64a()
+		h.Write([]byte(word))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return int64(fingerprint)
+}
+
+// contentHash è l'hash del testo normalizzato: due testi che differiscono
+// solo per maiuscole, accenti, punteggiatura o spazi hanno lo stesso hash.
+// Un testo senza parole ha hash 0, che non viene confrontato.
+func contentHash(text string) int64 {
+	words := normalizedWords(text)
+	if len(words) == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(words, " ")))
+	return int64(h.Sum64())
+}
+
+// simhashBand restituisce la chiave del bucket della banda b dell'impronta
+func simhashBand(fingerprint int64, b int) int {
+	width := 64 / SIMHASH_BANDS
+	value := (uint64(fingerprint) >> (b * width)) & (1<<width - 1)
+	return b<<width | int(value)
+}
+
+// groupNearDuplicates raggruppa gli elementi le cui impronte distano al più
+// maxDistance bit, anche indirettamente (A~B e B~C mettono A e C insieme),
+// oltre a quelli che condividono già un gruppo. Confronta solo le coppie
+// con una banda in comune. Restituisce il gruppo di ogni elemento che ha
+// almeno un compagno: il più piccolo fra gli ID e i gruppi esistenti.
+func groupNearDuplicates(items []DupCandidate, maxDistance int) map[int]int {
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		if ri, rj := find(i), find(j); ri != rj {
+			parent[ri] = rj
+		}
+	}
+
+	buckets := make(map[int][]int)
+	byGroup := make(map[int]int)
+	for i, item := range items {
+		if item.Group != nil {
+			if j, ok := byGroup[*item.Group]; ok {
+				union(i, j)
+			} else {
+				byGroup[*item.Group] = i
+			}
+		}
+		for b := 0; b < SIMHASH_BANDS; b++ {
+			key := simhashBand(item.Simhash, b)
+			for _, j := range buckets[key] {
+				if find(i) != find(j) && bits.OnesCount64(uint64(item.Simhash^items[j].Simhash)) <= maxDistance {
+					union(i, j)
+				}
+			}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	size := make(map[int]int)
+	first := make(map[int]int)
+	for i, item := range items {
+		root := find(i)
+		size[root]++
+		group := item.ID
+		if item.Group != nil {
+			group = min(group, *item.Group)
+		}
+		if g, ok := first[root]; !ok || group < g {
+			first[root] = group
+		}
+	}
+
+	groups := make(map[int]int)
+	for i, item := range items {
+		if root := find(i); size[root] > 1 {
+			groups[item.ID] = first[root]
+		}
+	}
+	return groups
+}
+
+// groupPendingDuplicates ricalcola i gruppi di quasi duplicati della coda in
+// attesa. Restituisce quanti elementi sono entrati in un gruppo.
+func groupPendingDuplicates(db *pgx.Conn, kind *ContentKind) (int, error) {
+	items, err := QueryPendingFingerprints(db, kind)
+	if err != nil {
+		return 0, err
+	}
+	groups := groupNearDuplicates(items, SIMHASH_MAX_DISTANCE)
+
+	changed := make(map[int]int)
+	grouped := 0
+	for _, item := range items {
+		group, ok := groups[item.ID]
+		if !ok || (item.Group != nil && *item.Group == group) {
+			continue
+		}
+		changed[item.ID] = group
+		if item.Group == nil {
+			grouped++
+		}
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	return grouped, SetDupGroups(db, kind, changed)
+}
+
+// burstThreshold restituisce la soglia configurata; zero la disattiva
+func burstThreshold() (int, time.Duration) {
+	return CONF.BURST.ITEMS, time.Duration(CONF.BURST.WINDOW_MINUTES) * time.Minute
+}
+
+// fingerprintContent calcola l'impronta degli elementi che non l'hanno
+// ancora (prima quelli in attesa, poi lo storico dal più recente). Gli
+// elementi in attesa vengono confrontati con i contenuti già rifiutati,
+// raggruppati con i quasi duplicati già in coda e controllati per le
+// raffiche dello stesso autore.
+// Restituisce quanti elementi sono stati segnalati.
+func fingerprintContent(db *pgx.Conn) (int, error) {
+	flagged := 0
+	burstItems, burstWindow := burstThreshold()
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		if err := recordPastRejections(db, kind); err != nil {
+			return flagged, err
+		}
+		missing, err := QueryMissingFingerprints(db, kind, FINGERPRINT_BATCH)
+		if err != nil {
+			return flagged, err
+		}
+		regroup := false
+		for _, m := range missing {
+			fingerprint := simhash(m.Content)
+			if err := SetFingerprint(db, kind, m.ID, fingerprint); err != nil {
+				return flagged, err
+			}
+			if m.Status != "received" {
+				continue
+			}
+
+			matched, rejected, err := checkResubmission(db, kind, m.ID, m.Content, fingerprint)
+			if err != nil {
+				println("Resubmission check error ("+kind.Name+"):", err.Error())
+			}
+			if matched {
+				flagged++
+			}
+			if rejected {
+				continue
+			}
+
+			if fingerprint != 0 {
+				regroup = true
+			}
+
+			if burstItems > 0 && burstWindow > 0 {
+				count, err := FlagBurst(db, kind, m.CreatorID, m.CreationTimestamp, burstWindow, burstItems)
+				if err != nil {
+					return flagged, err
+				}
+				flagged += int(count)
+			}
+		}
+
+		// I gruppi si ricalcolano una volta sola, dopo tutte le nuove impronte
+		if regroup {
+			grouped, err := groupPendingDuplicates(db, kind)
+			if err != nil {
+				return flagged, err
+			}
+			flagged += grouped
+		}
+	}
+	return flagged, nil
+}
+
+// startFingerprinter avvia in background il calcolo delle impronte
+func startFingerprinter() {
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("Fingerprint: cannot connect to database:", err.Error())
+			} else {
+				count, err := fingerprintContent(db)
+				if err != nil {
+					println("Fingerprint error:", err.Error())
+				} else if count > 0 {
+					println("Fingerprint: flagged", count, "re-submitted, near-duplicate or burst items")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(FINGERPRINT_INTERVAL)
+		}
+	}()
+}
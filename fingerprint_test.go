@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestSimhash(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		maxDist int
+	}{
+		{"stesso testo", "ciao a tutti quanti", "ciao a tutti quanti", 0},
+		{"maiuscole e punteggiatura", "Ciao a tutti, quanti!", "ciao a tutti quanti", 0},
+		{"leet e lettere ripetute", "C14O   ciaooo", "ciao ciao", 0},
+		{"una parola cambiata", "domani al bar della scuola alle otto ci vediamo tutti", "domani al bar della scuola alle nove ci vediamo tutti", SIMHASH_MAX_DISTANCE * 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := bits.OnesCount64(uint64(simhash(tt.a) ^ simhash(tt.b)))
+			if d > tt.maxDist {
+				t.Errorf("distanza %d, attesa al più %d", d, tt.maxDist)
+			}
+		})
+	}
+
+	if simhash("") != 0 || simhash(" ... !!") != 0 {
+		t.Error("un testo senza parole deve avere impronta 0")
+	}
+}
+
+func TestSimhashBandPigeonhole(t *testing.T) {
+	// Con al più SIMHASH_MAX_DISTANCE bit diversi almeno una banda coincide
+	if SIMHASH_MAX_DISTANCE >= SIMHASH_BANDS {
+		t.Fatalf("SIMHASH_MAX_DISTANCE (%d) deve essere minore di SIMHASH_BANDS (%d)", SIMHASH_MAX_DISTANCE, SIMHASH_BANDS)
+	}
+	a := int64(0x0123456789abcdef)
+	b := a ^ int64(0x0101010101010000) // un bit diverso in sei bande
+	shared := 0
+	for band := 0; band < SIMHASH_BANDS; band++ {
+		if simhashBand(a, band) == simhashBand(b, band) {
+			shared++
+		}
+	}
+	if shared != 2 {
+		t.Errorf("bande in comune %d, attese 2", shared)
+	}
+}
+
+func TestGroupNearDuplicates(t *testing.T) {
+	group := func(g int) *int { return &g }
+	tests := []struct {
+		name  string
+		items []DupCandidate
+		want  map[int]int
+	}{
+		{
+			name:  "nessun quasi duplicato",
+			items: []DupCandidate{{ID: 1, Simhash: 0x00ff}, {ID: 2, Simhash: 0x7f00ff00ff00}},
+			want:  map[int]int{},
+		},
+		{
+			name:  "coppia vicina",
+			items: []DupCandidate{{ID: 5, Simhash: 0xf0f0}, {ID: 3, Simhash: 0xf0f1}},
+			want:  map[int]int{3: 3, 5: 3},
+		},
+		{
+			// 1 e 3 distano 8 bit, ma sono entrambi vicini a 2
+			name: "transitivo",
+			items: []DupCandidate{
+				{ID: 1, Simhash: 0x0000000000000000},
+				{ID: 2, Simhash: 0x000000000000000f},
+				{ID: 3, Simhash: 0x00000000000000ff},
+			},
+			want: map[int]int{1: 1, 2: 1, 3: 1},
+		},
+		{
+			name: "unisce due gruppi esistenti",
+			items: []DupCandidate{
+				{ID: 10, Simhash: 0x0f, Group: group(4)},
+				{ID: 11, Simhash: 0x7f7f7f7f00000000, Group: group(7)},
+				{ID: 12, Simhash: 0x0e},
+				{ID: 13, Simhash: 0x7f7f7f7f00000001},
+				{ID: 14, Simhash: 0x0c, Group: group(7)},
+			},
+			want: map[int]int{10: 4, 11: 4, 12: 4, 13: 4, 14: 4},
+		},
+		{
+			name:  "gruppo esistente rimasto solo",
+			items: []DupCandidate{{ID: 8, Simhash: 0x0f, Group: group(2)}, {ID: 9, Simhash: 0x7f7f7f7f00000000}},
+			want:  map[int]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupNearDuplicates(tt.items, SIMHASH_MAX_DISTANCE)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for id, g := range tt.want {
+				if got[id] != g {
+					t.Errorf("elemento %d: gruppo %d, atteso %d", id, got[id], g)
+				}
+			}
+		})
+	}
+}
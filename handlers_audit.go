@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func handleGetAudit(ctx *gin.Context) {
+	page, pageSize, ok := parsePagination(ctx)
+	if !ok {
+		return
+	}
+
+	filter := AuditFilter{
+		Action:     ctx.Query("action"),
+		TargetType: ctx.Query("target_type"),
+	}
+	if filter.ActorID, ok = queryInt(ctx, "actor_id"); !ok {
+		return
+	}
+	if filter.TargetID, ok = queryInt(ctx, "target_id"); !ok {
+		return
+	}
+	if filter.From, ok = queryTime(ctx, "from"); !ok {
+		return
+	}
+	if filter.To, ok = queryTime(ctx, "to"); !ok {
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	total, err := CountAuditEntries(db, filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying audit log",
+		})
+		return
+	}
+
+	rows, err := QueryAuditEntries(db, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying audit log",
+		})
+		return
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(
+			&e.ID, &e.CreatedAt, &e.ActorID, &e.ActorRole, &e.ActorName,
+			&e.Action, &e.TargetType, &e.TargetID, &e.Before, &e.After,
+			&e.IP, &e.RequestID,
+		); err != nil {
+			println("Scan error (audit):", err.Error())
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	ctx.JSON(http.StatusOK, PagedResponse{
+		Status:   "ok",
+		Data:     entries,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Numero massimo di elementi trattati da una singola azione massiva
+const MAX_BULK_SIZE = 500
+
+var validBulkActions = map[string]bool{"approve": true, "reject": true, "set_status": true, "delete": true, "dismiss_reports": true}
+
+var validBulkQueues = map[string]bool{QUEUE_ALL: true, QUEUE_PENDING: true, QUEUE_REPORTED: true}
+
+// bulkOperation restituisce l'azione da applicare a un singolo elemento,
+// con la relativa voce di audit (stessa action delle route singole).
+func bulkOperation(ctx *gin.Context, kind *ContentKind, req BulkRequest, reason, note *string) func(db DBTX, id int) error {
+	return func(db DBTX, id int) error {
+		entry := newAuditEntry(ctx, req.Action, kind.Name, id)
+		switch req.Action {
+		case "approve":
+			return auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+				return ApproveContent(tx, kind, id)
+			})
+		case "reject":
+			return auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+				return RejectContent(tx, kind, id, reason, note)
+			})
+		case "set_status":
+			return auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+				return SetContentStatus(tx, kind, id, req.Status, reason, note)
+			})
+		case "dismiss_reports":
+			_, err := auditedDismissReports(db, entry, kind, id)
+			return err
+		default:
+			_, err := auditedDelete(db, entry, kind, id)
+			return err
+		}
+	}
+}
+
+// bulkItemError converte l'errore di un elemento nell'esito da restituire
+func bulkItemError(kind *ContentKind, id int, err error) BulkItemResult {
+	result := BulkItemResult{ID: id, Result: "error"}
+	if errors.Is(err, errNoOpenReports) {
+		result.Error = "no_open_reports"
+		result.Msg = kind.Label + " has no open reports"
+		return result
+	}
+	var conflict *claimConflictError
+	if errors.As(err, &conflict) {
+		result.Error = "claimed_by_other"
+		result.Msg = kind.Label + " is " + conflict.Error()
+		return result
+	}
+	status, code, _ := classifyDbError(err)
+	switch status {
+	case http.StatusNotFound:
+		result.Error = kind.Name + "_not_found"
+		result.Msg = kind.Label + " not found"
+	case http.StatusInternalServerError:
+		result.Error = "update_error"
+		result.Msg = "Error updating " + kind.Name
+	default:
+		result.Error = code
+		result.Msg = "Invalid value for " + kind.Name
+	}
+	return result
+}
+
+// bulkHeld traduce il filtro held: senza, la coda in attesa esclude i trattenuti
+func bulkHeld(held bool) string {
+	if held {
+		return HELD_ONLY
+	}
+	return ""
+}
+
+// uniqueIds rimuove i duplicati mantenendo l'ordine
+func uniqueIds(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// handleBulkContent applica la stessa azione a una lista di elementi (per ID
+// o per filtro). In modalità atomica tutto avviene in una transazione e il
+// primo errore annulla l'intera richiesta; altrimenti ogni elemento è
+// indipendente e la risposta riporta l'esito di ciascuno.
+func handleBulkContent(ctx *gin.Context) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return
+	}
+
+	var req BulkRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	if !validBulkActions[req.Action] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_action",
+			Msg:    "Action must be: approve, reject, set_status, delete, or dismiss_reports",
+		})
+		return
+	}
+
+	if req.Action == "set_status" && !validContentStatuses[req.Status] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_status",
+			Msg:    "Status must be: received, approved, or rejected",
+		})
+		return
+	}
+
+	if (len(req.IDs) == 0) == (req.Filter == nil) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_selection",
+			Msg:    "Provide either a non-empty ids list or a filter",
+		})
+		return
+	}
+
+	if len(req.IDs) > MAX_BULK_SIZE {
+		ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+			Status: "error",
+			Error:  "bulk_too_large",
+			Msg:    "At most " + strconv.Itoa(MAX_BULK_SIZE) + " items per request",
+		})
+		return
+	}
+
+	var params ContentListParams
+	if req.Filter != nil {
+		f := req.Filter
+		if f.Queue == "" {
+			f.Queue = QUEUE_ALL
+		}
+		if !validBulkQueues[f.Queue] {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_queue",
+				Msg:    "Filter queue must be: all, pending, or reported",
+			})
+			return
+		}
+		if f.Status != "" && !validContentStatuses[f.Status] {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_status",
+				Msg:    "Filter status must be: received, approved, or rejected",
+			})
+			return
+		}
+		params = ContentListParams{
+			Queue:         f.Queue,
+			Status:        f.Status,
+			CityID:        f.CityID,
+			SchoolID:      f.SchoolID,
+			CreatorID:     f.CreatorID,
+			From:          f.From,
+			To:            f.To,
+			MinReports:    f.MinReports,
+			DupGroup:      f.DupGroup,
+			Burst:         f.Burst,
+			Resubmissions: f.Resubmissions,
+			Held:          bulkHeld(f.Held),
+		}
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	ids := uniqueIds(req.IDs)
+	if req.Filter != nil {
+		// Uno in più del massimo per accorgersi che il filtro è troppo largo
+		ids, err = QueryContentIds(db, kind, params, MAX_BULK_SIZE+1)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status: "error",
+				Error:  "query_error",
+				Msg:    "Error selecting " + kind.Name,
+			})
+			return
+		}
+		if len(ids) > MAX_BULK_SIZE {
+			ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+				Status: "error",
+				Error:  "bulk_too_large",
+				Msg:    "Filter matches more than " + strconv.Itoa(MAX_BULK_SIZE) + " items, narrow it down",
+			})
+			return
+		}
+	}
+
+	var reason, note *string
+	if req.Action == "reject" || (req.Action == "set_status" && req.Status == "rejected") {
+		if reason, note, ok = parseRejection(ctx, db, req.Reason, req.Note); !ok {
+			return
+		}
+	}
+
+	op := bulkOperation(ctx, kind, req, reason, note)
+	response := BulkResponse{
+		Status:    "ok",
+		Action:    req.Action,
+		Atomic:    req.Atomic,
+		Requested: len(ids),
+		Results:   make([]BulkItemResult, 0, len(ids)),
+	}
+
+	if req.Atomic {
+		tx, err := db.Begin(context.Background())
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status: "error",
+				Error:  "update_error",
+				Msg:    "Error starting transaction",
+			})
+			return
+		}
+		defer tx.Rollback(context.Background())
+
+		for i, id := range ids {
+			if err := op(tx, id); err != nil {
+				// Annulla tutto: i precedenti tornano indietro, i successivi non partono
+				for _, done := range ids[:i] {
+					response.Results = append(response.Results, BulkItemResult{ID: done, Result: "rolled_back"})
+				}
+				response.Results = append(response.Results, bulkItemError(kind, id, err))
+				for _, rest := range ids[i+1:] {
+					response.Results = append(response.Results, BulkItemResult{ID: rest, Result: "skipped"})
+				}
+				response.Status = "error"
+				response.Error = "bulk_aborted"
+				response.Msg = "Item " + strconv.Itoa(id) + " failed, no changes were applied"
+				response.Failed = 1
+				ctx.JSON(http.StatusConflict, response)
+				return
+			}
+			response.Results = append(response.Results, BulkItemResult{ID: id, Result: "ok"})
+		}
+
+		if err := tx.Commit(context.Background()); err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status: "error",
+				Error:  "update_error",
+				Msg:    "Error committing bulk " + req.Action,
+			})
+			return
+		}
+		response.Succeeded = len(ids)
+	} else {
+		for _, id := range ids {
+			if err := op(db, id); err != nil {
+				response.Results = append(response.Results, bulkItemError(kind, id, err))
+				response.Failed++
+				continue
+			}
+			response.Results = append(response.Results, BulkItemResult{ID: id, Result: "ok"})
+			response.Succeeded++
+		}
+	}
+
+	response.Msg = "Bulk " + req.Action + ": " + strconv.Itoa(response.Succeeded) + " of " +
+		strconv.Itoa(response.Requested) + " " + kind.Name + " items processed"
+	ctx.JSON(http.StatusOK, response)
+}
@@ -77,11 +77,7 @@ func handleAddCity(ctx *gin.Context) {
 	defer db.Close(ctx)
 
 	if err := InsertCity(db, req.Name, req.Region); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "insert_error",
-			Msg:    "Error inserting city: " + err.Error(),
-		})
+		respondDbError(ctx, err, "city", "insert_error", "Error inserting city")
 		return
 	}
 
@@ -125,11 +121,7 @@ func handleUpdateCity(ctx *gin.Context) {
 	defer db.Close(ctx)
 
 	if err := UpdateCity(db, id, req.Name, req.Region); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "update_error",
-			Msg:    "Error updating city",
-		})
+		respondDbError(ctx, err, "city", "update_error", "Error updating city")
 		return
 	}
 
@@ -163,11 +155,7 @@ func handleDeleteCity(ctx *gin.Context) {
 	defer db.Close(ctx)
 
 	if err := DeleteCity(db, id); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "delete_error",
-			Msg:    "Error deleting city. Make sure no schools reference this city.",
-		})
+		respondDbError(ctx, err, "city", "delete_error", "Error deleting city")
 		return
 	}
 
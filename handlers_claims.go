@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// respondClaimConflict risponde 409 se err indica un elemento riservato da
+// un altro moderatore; restituisce true se ha scritto la risposta.
+func respondClaimConflict(ctx *gin.Context, kind *ContentKind, err error) bool {
+	var conflict *claimConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	ctx.JSON(http.StatusConflict, ErrorResponse{
+		Status: "error",
+		Error:  "claimed_by_other",
+		Msg:    kind.Label + " is " + conflict.Error(),
+	})
+	return true
+}
+
+// claimModerator restituisce il moderatore della sessione; le riserve sono
+// personali, quindi l'account statico non può usarle.
+func claimModerator(ctx *gin.Context) (int, bool) {
+	moderatorId := ctx.GetInt("moderator_id")
+	if moderatorId <= 0 {
+		ctx.JSON(http.StatusForbidden, ErrorResponse{
+			Status: "error",
+			Error:  "claim_requires_moderator",
+			Msg:    "Claims require a personal moderator account",
+		})
+		return 0, false
+	}
+	return moderatorId, true
+}
+
+// requireAdmin verifica che il moderatore possa forzare le riserve altrui;
+// is_admin è letto dal database da authMiddleware a ogni richiesta
+func requireAdmin(ctx *gin.Context) bool {
+	if !ctx.GetBool("is_admin") {
+		ctx.JSON(http.StatusForbidden, ErrorResponse{
+			Status: "error",
+			Error:  "admin_required",
+			Msg:    "Only admins can override another moderator's claim",
+		})
+		return false
+	}
+	return true
+}
+
+// handleClaimNextContent riserva e restituisce i prossimi elementi in attesa
+// (?count=, default 10) per la durata del lease configurato.
+func handleClaimNextContent(ctx *gin.Context) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return
+	}
+	moderatorId, ok := claimModerator(ctx)
+	if !ok {
+		return
+	}
+
+	count := DEFAULT_CLAIM_BATCH
+	if v := ctx.Query("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MAX_CLAIM_BATCH {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_count",
+				Msg:    "count must be between 1 and " + strconv.Itoa(MAX_CLAIM_BATCH),
+			})
+			return
+		}
+		count = n
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	lease := claimLeaseMinutes()
+	ids, err := ClaimNextContent(db, kind, moderatorId, count, lease)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "claim_error",
+			Msg:    "Error claiming pending " + kind.Name,
+		})
+		return
+	}
+
+	items := []ContentItem{}
+	if len(ids) > 0 {
+		params := ContentListParams{Queue: QUEUE_PENDING, Sort: "oldest", Limit: len(ids), IDs: ids}
+		rows, err := QueryContentList(db, kind, params)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status: "error",
+				Error:  "query_error",
+				Msg:    "Error querying claimed " + kind.Name,
+			})
+			return
+		}
+		for rows.Next() {
+			item, err := scanContentItem(rows, kind)
+			if err != nil {
+				println("Scan error ("+kind.Name+"):", err.Error())
+				continue
+			}
+			items = append(items, item)
+		}
+		rows.Close()
+		// Stesse informazioni della coda in attesa
+		annotateQueue(db, kind, QUEUE_PENDING, items)
+	}
+
+	ctx.JSON(http.StatusOK, ClaimNextResponse{
+		Status:         "ok",
+		Data:           items,
+		LeaseExpiresAt: time.Now().Add(time.Duration(lease) * time.Minute),
+	})
+}
+
+// handleClaimContent riserva un singolo elemento (o rinnova la propria
+// riserva). Con ?steal=true un admin può prendere la riserva di un altro.
+func handleClaimContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	moderatorId, ok := claimModerator(ctx)
+	if !ok {
+		return
+	}
+	steal := ctx.Query("steal") == "true"
+	if steal && !requireAdmin(ctx) {
+		return
+	}
+
+	previous, err := QueryActiveClaim(db, kind, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying claim",
+		})
+		return
+	}
+
+	var claimed bool
+	if steal && previous != nil && previous.ModeratorID != moderatorId {
+		// Prendere la riserva di un altro è un'azione amministrativa: va nel registro
+		err = runAudited(db, newAuditEntry(ctx, "steal_claim", kind.Name, id), func(tx pgx.Tx) (any, any, error) {
+			var err error
+			claimed, err = ClaimContent(tx, kind, id, moderatorId, claimLeaseMinutes(), true)
+			if err != nil {
+				return nil, nil, err
+			}
+			return map[string]any{"claimed_by": previous.ModeratorID, "claimed_by_name": previous.ModeratorName},
+				map[string]any{"claimed_by": moderatorId}, nil
+		})
+	} else {
+		claimed, err = ClaimContent(db, kind, id, moderatorId, claimLeaseMinutes(), false)
+	}
+	if errors.Is(err, errNotPending) {
+		ctx.JSON(http.StatusConflict, ErrorResponse{
+			Status: "error",
+			Error:  "not_pending",
+			Msg:    "Only pending " + kind.Name + " items can be claimed",
+		})
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "claim_error", "Error claiming "+kind.Name)
+		return
+	}
+	claim, err := QueryActiveClaim(db, kind, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying claim",
+		})
+		return
+	}
+	// Se nel frattempo un altro moderatore l'ha riservato, la riserva è sua
+	if !claimed || claim == nil || claim.ModeratorID != moderatorId {
+		if claim == nil {
+			claim = previous
+		}
+		if claim == nil {
+			ctx.JSON(http.StatusConflict, ErrorResponse{
+				Status: "error",
+				Error:  "claimed_by_other",
+				Msg:    kind.Label + " was claimed by another moderator",
+			})
+			return
+		}
+		respondClaimConflict(ctx, kind, &claimConflictError{Claim: *claim})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ClaimResponse{
+		Status: "ok",
+		Msg:    kind.Label + " claimed until " + claim.ExpiresAt.Format("15:04"),
+		Claim:  *claim,
+	})
+}
+
+// handleReleaseClaim rilascia la propria riserva; con ?force=true un admin
+// può rilasciare quella di un altro moderatore.
+func handleReleaseClaim(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	moderatorId, ok := claimModerator(ctx)
+	if !ok {
+		return
+	}
+	force := ctx.Query("force") == "true"
+	if force && !requireAdmin(ctx) {
+		return
+	}
+
+	claim, err := QueryActiveClaim(db, kind, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying claim",
+		})
+		return
+	}
+	if claim == nil {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{
+			Status: "error",
+			Error:  "claim_not_found",
+			Msg:    kind.Label + " is not claimed",
+		})
+		return
+	}
+
+	if claim.ModeratorID == moderatorId {
+		err = DeleteClaim(db, kind, id)
+	} else if force {
+		err = runAudited(db, newAuditEntry(ctx, "release_claim", kind.Name, id), func(tx pgx.Tx) (any, any, error) {
+			if err := DeleteClaim(tx, kind, id); err != nil {
+				return nil, nil, err
+			}
+			return map[string]any{"claimed_by": claim.ModeratorID, "claimed_by_name": claim.ModeratorName}, nil, nil
+		})
+	} else {
+		respondClaimConflict(ctx, kind, &claimConflictError{Claim: *claim})
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "claim_error", "Error releasing claim on "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    "Claim released",
+	})
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// annotateQueue allega le informazioni previste dalla coda: in attesa i
+// termini vietati, gli studenti nominati e i reinvii di contenuti già
+// rifiutati; in attesa e segnalati i dati personali
+func annotateQueue(db DBTX, kind *ContentKind, queue string, items []ContentItem) {
+	if queue == QUEUE_PENDING {
+		annotateTermMatches(db, items)
+		annotateMentions(db, kind, items)
+		annotateResubmissions(db, kind, items)
+	}
+	if queue == QUEUE_PENDING || queue == QUEUE_REPORTED {
+		annotatePII(items)
+	}
+}
+
+// listContent legge i parametri della lista, esegue conteggio e pagina e
+// serializza gli elementi con il cursore per la pagina successiva.
+func listContent(ctx *gin.Context, connect func() (*pgx.Conn, error), queue, defaultSort, errMsg string) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return
+	}
+
+	params, ok := parseContentListParams(ctx, queue, defaultSort)
+	if !ok {
+		return
+	}
+
+	db, err := connect()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	total, err := CountContentList(db, kind, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    errMsg + " " + kind.Name,
+		})
+		return
+	}
+
+	rows, err := QueryContentList(db, kind, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    errMsg + " " + kind.Name,
+		})
+		return
+	}
+	defer rows.Close()
+
+	items := []ContentItem{}
+	for rows.Next() {
+		item, err := scanContentItem(rows, kind)
+		if err != nil {
+			println("Scan error ("+kind.Name+"):", err.Error())
+			continue
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+
+	var nextCursor *string
+	if len(items) > params.Limit {
+		items = items[:params.Limit]
+		cursor := encodeCursor(cursorAfter(items[len(items)-1], params.Sort))
+		nextCursor = &cursor
+	}
+
+	annotateQueue(db, kind, queue, items)
+
+	ctx.JSON(http.StatusOK, CursorResponse{
+		Status:     "ok",
+		Data:       items,
+		Total:      total,
+		PageSize:   params.Limit,
+		NextCursor: nextCursor,
+	})
+}
+
+func handleGetPendingContent(ctx *gin.Context) {
+	listContent(ctx, makeDbaseConnection, QUEUE_PENDING, "priority", "Error querying pending")
+}
+
+func handleGetReportedContent(ctx *gin.Context) {
+	listContent(ctx, makeDbaseConnection, QUEUE_REPORTED, "most_reported", "Error querying reported")
+}
+
+func handleGetAllContent(ctx *gin.Context) {
+	listContent(ctx, makeReadConnection, QUEUE_ALL, "newest", "Error querying")
+}
+
+// handleSearchContent esegue la ricerca full-text, combinabile con gli stessi
+// filtri delle liste (status, city_id, school_id, creator_id, from, to).
+func handleSearchContent(ctx *gin.Context) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return
+	}
+
+	search := strings.TrimSpace(ctx.Query("q"))
+	if len([]rune(search)) < 2 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "search_term_too_short",
+			Msg:    "Search term must be at least 2 characters (use ?q=...)",
+		})
+		return
+	}
+
+	page, pageSize, ok := parsePagination(ctx)
+	if !ok {
+		return
+	}
+	params, ok := parseContentListParams(ctx, QUEUE_ALL, "newest")
+	if !ok {
+		return
+	}
+	params.Limit = pageSize
+
+	db, err := makeReadConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	total, err := CountContentSearch(db, kind, search, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error searching " + kind.Name,
+		})
+		return
+	}
+
+	rows, err := QueryContentSearch(db, kind, search, params, (page-1)*pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error searching " + kind.Name,
+		})
+		return
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var result SearchResult
+		item, err := scanContentItem(rows, kind, &result.Rank, &result.Snippet)
+		if err != nil {
+			println("Scan error (search "+kind.Name+"):", err.Error())
+			continue
+		}
+		result.ContentItem = item
+		results = append(results, result)
+	}
+
+	ctx.JSON(http.StatusOK, PagedResponse{
+		Status:   "ok",
+		Data:     results,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// contentActionTarget valida tipo e ID di una route /:kind/:id/... e apre la connessione
+func contentActionTarget(ctx *gin.Context) (*ContentKind, int, *pgx.Conn, bool) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return nil, 0, nil, false
+	}
+
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_id",
+			Msg:    "Invalid " + kind.Name + " ID",
+		})
+		return nil, 0, nil, false
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return nil, 0, nil, false
+	}
+
+	return kind, id, db, true
+}
+
+func handleApproveContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	// Il corpo è facoltativo: con publish_at l'elemento viene pubblicato più
+	// tardi, senza resta l'approvazione immediata
+	var req ScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+	if req.PublishAt != nil {
+		scheduleContent(ctx, db, kind, id, *req.PublishAt, false)
+		return
+	}
+
+	entry := newAuditEntry(ctx, "approve", kind.Name, id)
+	err := auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+		return ApproveContent(tx, kind, id)
+	})
+	if respondClaimConflict(ctx, kind, err) {
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "update_error", "Error approving "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    kind.Label + " approved successfully",
+	})
+}
+
+func handleRejectContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	// Il corpo è facoltativo: senza motivo il rifiuto resta valido se la
+	// configurazione non lo richiede
+	var req RejectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	reason, note, ok := parseRejection(ctx, db, req.Reason, req.Note)
+	if !ok {
+		return
+	}
+
+	entry := newAuditEntry(ctx, "reject", kind.Name, id)
+	err := auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+		return RejectContent(tx, kind, id, reason, note)
+	})
+	if respondClaimConflict(ctx, kind, err) {
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "update_error", "Error rejecting "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    kind.Label + " rejected successfully",
+	})
+}
+
+func handleDeleteContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	result, err := auditedDelete(db, newAuditEntry(ctx, "delete", kind.Name, id), kind, id)
+	if respondClaimConflict(ctx, kind, err) {
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "delete_error", "Error deleting "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DeleteResponse{
+		Status:       "ok",
+		Msg:          kind.Label + " moved to trash",
+		DeleteResult: result,
+	})
+}
+
+func handleSetContentStatus(ctx *gin.Context) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return
+	}
+
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_id",
+			Msg:    "Invalid " + kind.Name + " ID",
+		})
+		return
+	}
+
+	var req SetStatusRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	// Validate status
+	if !validContentStatuses[req.Status] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_status",
+			Msg:    "Status must be: received, approved, or rejected",
+		})
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	var reason, note *string
+	if req.Status == "rejected" {
+		if reason, note, ok = parseRejection(ctx, db, req.Reason, req.Note); !ok {
+			return
+		}
+	}
+
+	entry := newAuditEntry(ctx, "set_status", kind.Name, id)
+	err = auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+		return SetContentStatus(tx, kind, id, req.Status, reason, note)
+	})
+	if respondClaimConflict(ctx, kind, err) {
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "update_error", "Error updating "+kind.Name+" status")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    kind.Label + " status updated successfully",
+	})
+}
+
+// handleGetContentItem restituisce un singolo elemento con le stesse
+// evidenziazioni della coda in attesa (termini, dati personali, menzioni)
+func handleGetContentItem(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	rows, err := QueryContentList(db, kind, ContentListParams{
+		Queue: QUEUE_ALL,
+		Sort:  "newest",
+		Limit: 1,
+		Held:  HELD_INCLUDE,
+		IDs:   []int{id},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying " + kind.Name,
+		})
+		return
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ContentItem, error) {
+		return scanContentItem(rows, kind)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying " + kind.Name,
+		})
+		return
+	}
+	if len(items) == 0 {
+		respondDbError(ctx, ErrNotFound, kind.Name, "query_error", "Error querying "+kind.Name)
+		return
+	}
+
+	// Il dettaglio di un elemento ha tutte le informazioni della coda in attesa
+	annotateQueue(db, kind, QUEUE_PENDING, items)
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   items[0],
+	})
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseEventFilter legge l'ambito dello stream: ?kinds=post,spotted,
+// ?types=pending_new,report_new,status_changed, ?city_id=, ?school_id=
+func parseEventFilter(ctx *gin.Context) (eventFilter, bool) {
+	filter := eventFilter{
+		Kinds:       map[string]bool{},
+		Types:       map[string]bool{},
+		ModeratorID: ctx.GetInt("moderator_id"),
+	}
+	var ok bool
+
+	if v := ctx.Query("kinds"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if _, exists := CONTENT_KINDS[name]; !exists {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{
+					Status: "error",
+					Error:  "invalid_kinds",
+					Msg:    "kinds must be a comma-separated list of: " + strings.Join(contentKindNames(), ", "),
+				})
+				return filter, false
+			}
+			filter.Kinds[name] = true
+		}
+	}
+
+	if v := ctx.Query("types"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if !validEventTypes[name] {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{
+					Status: "error",
+					Error:  "invalid_types",
+					Msg:    "types must be a comma-separated list of: pending_new, report_new, status_changed",
+				})
+				return filter, false
+			}
+			filter.Types[name] = true
+		}
+	}
+
+	if filter.CityID, ok = queryInt(ctx, "city_id"); !ok {
+		return filter, false
+	}
+	if filter.SchoolID, ok = queryInt(ctx, "school_id"); !ok {
+		return filter, false
+	}
+	return filter, true
+}
+
+// writeEvent scrive un evento nel formato SSE
+func writeEvent(ctx *gin.Context, ev ModerationEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(ctx.Writer, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data); err != nil {
+		return err
+	}
+	ctx.Writer.Flush()
+	return nil
+}
+
+// handleEventStream apre uno stream Server-Sent Events con gli eventi di
+// moderazione. Con l'header Last-Event-ID (o ?last_event_id=) vengono prima
+// reinviati gli eventi persi; se sono troppi arriva un evento "resync" e il
+// client deve ricaricare le liste.
+func handleEventStream(ctx *gin.Context) {
+	filter, ok := parseEventFilter(ctx)
+	if !ok {
+		return
+	}
+
+	lastIdStr := ctx.GetHeader("Last-Event-ID")
+	if lastIdStr == "" {
+		lastIdStr = ctx.Query("last_event_id")
+	}
+	var lastId int64
+	if lastIdStr != "" {
+		var err error
+		if lastId, err = strconv.ParseInt(lastIdStr, 10, 64); err != nil || lastId < 0 {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_last_event_id",
+				Msg:    "Last-Event-ID must be a non-negative integer",
+			})
+			return
+		}
+	}
+
+	// Ci si iscrive prima del recupero, così nessun evento cade nel mezzo
+	events := EVENT_HUB.subscribe()
+	defer EVENT_HUB.unsubscribe(events)
+
+	var missed []ModerationEvent
+	if lastId > 0 {
+		db, err := makeDbaseConnection()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status: "error",
+				Error:  "database_unreachable",
+				Msg:    "Cannot connect to database",
+			})
+			return
+		}
+		missed, err = QueryEventsAfter(db, lastId, EVENT_REPLAY_LIMIT+1)
+		db.Close(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status: "error",
+				Error:  "query_error",
+				Msg:    "Error querying missed events",
+			})
+			return
+		}
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	fmt.Fprintf(ctx.Writer, "retry: %d\n\n", EVENT_RECONNECT_DELAY.Milliseconds())
+	ctx.Writer.Flush()
+
+	// Il hub non inoltra mai due volte lo stesso evento ma può inoltrarne
+	// uno con ID più basso in ritardo: si saltano solo quelli già recuperati
+	replayed := map[int64]bool{}
+	if len(missed) > EVENT_REPLAY_LIMIT {
+		// Troppi eventi persi: meglio ricaricare tutto che inviarli uno a uno
+		fmt.Fprintf(ctx.Writer, "event: resync\ndata: {}\n\n")
+		ctx.Writer.Flush()
+		missed = nil
+	}
+	for _, ev := range missed {
+		if filter.match(ev) {
+			if err := writeEvent(ctx, ev); err != nil {
+				return
+			}
+		}
+		replayed[ev.ID] = true
+	}
+
+	heartbeat := time.NewTicker(EVENT_HEARTBEAT)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case ev, open := <-events:
+			if !open {
+				return
+			}
+			// Già inviato durante il recupero
+			if replayed[ev.ID] {
+				continue
+			}
+			if filter.match(ev) {
+				if err := writeEvent(ctx, ev); err != nil {
+					return
+				}
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
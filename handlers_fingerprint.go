@@ -0,0 +1,156 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DEFAULT_BURST_HOURS = 24
+	MAX_BURST_HOURS     = 24 * 30
+)
+
+// handleGetSimilarContent restituisce gli elementi quasi uguali a quello
+// indicato. L'impronta dell'elemento viene ricalcolata dal testo attuale,
+// quindi funziona anche se lo scanner non l'ha ancora esaminato.
+// ?max_distance= (bit diversi, default SIMHASH_MAX_DISTANCE), ?limit=, ?status=
+func handleGetSimilarContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	maxDistance, limit := SIMHASH_MAX_DISTANCE, DEFAULT_SIMILAR_LIMIT
+	if v, ok := queryInt(ctx, "max_distance"); !ok {
+		return
+	} else if v != nil {
+		if *v < 0 || *v > 32 {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_max_distance",
+				Msg:    "max_distance must be between 0 and 32",
+			})
+			return
+		}
+		maxDistance = *v
+	}
+	if v, ok := queryInt(ctx, "limit"); !ok {
+		return
+	} else if v != nil {
+		if *v < 1 || *v > MAX_SIMILAR_LIMIT {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_limit",
+				Msg:    "limit must be between 1 and " + strconv.Itoa(MAX_SIMILAR_LIMIT),
+			})
+			return
+		}
+		limit = *v
+	}
+	status := ctx.Query("status")
+	if status != "" && !validContentStatuses[status] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_status",
+			Msg:    "Status must be: received, approved, or rejected",
+		})
+		return
+	}
+
+	content, err := QueryContentFingerprint(db, kind, id)
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "query_error", "Error querying "+kind.Name)
+		return
+	}
+
+	results := []SimilarItem{}
+	fingerprint := simhash(content)
+	if fingerprint == 0 {
+		// Nessuna parola da confrontare
+		ctx.JSON(http.StatusOK, DataResponse{Status: "ok", Data: results})
+		return
+	}
+
+	rows, err := QuerySimilarContent(db, kind, id, fingerprint, status, maxDistance, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying similar " + kind.Name,
+		})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result SimilarItem
+		item, err := scanContentItem(rows, kind, &result.Distance)
+		if err != nil {
+			println("Scan error (similar "+kind.Name+"):", err.Error())
+			continue
+		}
+		result.ContentItem = item
+		results = append(results, result)
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   results,
+	})
+}
+
+// handleGetBursts elenca gli autori con elementi in attesa segnati come
+// raffica negli ultimi ?hours= (default DEFAULT_BURST_HOURS)
+func handleGetBursts(ctx *gin.Context) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return
+	}
+
+	hours := DEFAULT_BURST_HOURS
+	if v, ok := queryInt(ctx, "hours"); !ok {
+		return
+	} else if v != nil {
+		if *v < 1 || *v > MAX_BURST_HOURS {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_hours",
+				Msg:    "hours must be between 1 and " + strconv.Itoa(MAX_BURST_HOURS),
+			})
+			return
+		}
+		hours = *v
+	}
+
+	db, err := makeReadConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	bursts, err := QueryBursts(db, kind, hours)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying bursts for " + kind.Name,
+		})
+		return
+	}
+	if bursts == nil {
+		bursts = []ContentBurst{}
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   bursts,
+	})
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Elementi recenti mostrati nel profilo, su tutti i tipi
+const PROFILE_LATEST_ITEMS = 10
+
+// latestUserItems restituisce gli ultimi elementi dell'utente su tutti i tipi,
+// dal più recente, con il link al dettaglio
+func latestUserItems(db *pgx.Conn, userId int) ([]ProfileItem, error) {
+	items := []ProfileItem{}
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		rows, err := QueryContentList(db, kind, ContentListParams{
+			Queue:     QUEUE_ALL,
+			Sort:      "newest",
+			Limit:     PROFILE_LATEST_ITEMS,
+			CreatorID: &userId,
+			Held:      HELD_INCLUDE,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			item, err := scanContentItem(rows, kind)
+			if err != nil {
+				println("Scan error (profile "+kind.Name+"):", err.Error())
+				continue
+			}
+			items = append(items, ProfileItem{
+				ContentItem: item,
+				Link:        "/api/content/" + kind.Name + "/" + strconv.Itoa(item.ID),
+			})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.After(items[j].CreationTimestamp)
+	})
+	if len(items) > PROFILE_LATEST_ITEMS {
+		items = items[:PROFILE_LATEST_ITEMS]
+	}
+	return items, nil
+}
+
+// handleGetUserProfile restituisce il profilo di moderazione di un utente:
+// contenuti per stato, tasso di rifiuto, segnalazioni ricevute e inviate,
+// like ricevuti, anzianità, ultimi elementi, cambi di ruolo e sanzioni attive
+func handleGetUserProfile(ctx *gin.Context) {
+	userId, ok := userIdParam(ctx)
+	if !ok {
+		return
+	}
+
+	db, err := makeReadConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	user, err := QueryUser(db, userId)
+	if err != nil {
+		respondDbError(ctx, err, "user", "query_error", "Error getting user")
+		return
+	}
+	profile := UserProfile{User: user, ByKind: map[string]UserContentStats{}}
+
+	respondQueryError := func(what string) {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying " + what,
+		})
+	}
+
+	if profile.AccountCreatedAt, err = QueryUserCreatedAt(db, userId); err != nil {
+		respondQueryError("user")
+		return
+	}
+	if profile.AccountCreatedAt != nil {
+		days := int(time.Since(*profile.AccountCreatedAt).Hours() / 24)
+		profile.AccountAgeDays = &days
+	}
+
+	for _, name := range contentKindNames() {
+		stats, err := QueryUserContentStats(db, CONTENT_KINDS[name], userId)
+		if err != nil {
+			respondQueryError(name + " statistics")
+			return
+		}
+		profile.ByKind[name] = stats
+		t := &profile.Totals
+		t.Total += stats.Total
+		t.Pending += stats.Pending
+		t.Approved += stats.Approved
+		t.Rejected += stats.Rejected
+		t.Held += stats.Held
+		t.Deleted += stats.Deleted
+		t.LikesReceived += stats.LikesReceived
+		t.ReportsReceived += stats.ReportsReceived
+		t.OpenReports += stats.OpenReports
+		t.ReportsFiled += stats.ReportsFiled
+	}
+	if decided := profile.Totals.Approved + profile.Totals.Rejected; decided > 0 {
+		rate := float64(profile.Totals.Rejected) / float64(decided)
+		profile.RejectionRate = &rate
+	}
+
+	if profile.LatestItems, err = latestUserItems(db, userId); err != nil {
+		respondQueryError("latest items")
+		return
+	}
+
+	if profile.RoleHistory, err = QueryRoleHistory(db, userId); err != nil {
+		respondQueryError("role history")
+		return
+	}
+	if profile.RoleHistory == nil {
+		profile.RoleHistory = []RoleChange{}
+	}
+
+	sanctions, err := QueryUserSanctions(db, userId)
+	if err != nil {
+		respondQueryError("sanctions")
+		return
+	}
+	profile.ActiveSanctions = []UserSanction{}
+	for _, s := range sanctions.Sanctions {
+		if s.Active {
+			profile.ActiveSanctions = append(profile.ActiveSanctions, s)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   profile,
+	})
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	errRejectionReasonRequired = errors.New("a rejection reason is required")
+	errRejectionNoteTooLong    = errors.New("rejection note is too long")
+	errUnknownRejectionReason  = errors.New("unknown or inactive rejection reason")
+)
+
+// Stesso vincolo della CHECK in 006_rejection_reasons.sql
+var rejectionCodePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// resolveRejection valida motivo e nota di un rifiuto e li restituisce pronti
+// per le query (nil se vuoti). Il motivo deve esistere ed essere attivo; è
+// obbligatorio se require_rejection_reason è attivo in configurazione.
+func resolveRejection(db DBTX, reason, note string) (*string, *string, error) {
+	reason = strings.TrimSpace(reason)
+	note = strings.TrimSpace(note)
+
+	if len([]rune(note)) > MAX_REJECTION_NOTE {
+		return nil, nil, errRejectionNoteTooLong
+	}
+
+	var reasonPtr, notePtr *string
+	if note != "" {
+		notePtr = &note
+	}
+
+	if reason == "" {
+		if CONF.REQUIRE_REJECTION_REASON {
+			return nil, nil, errRejectionReasonRequired
+		}
+		return nil, notePtr, nil
+	}
+
+	r, err := QueryRejectionReason(db, reason)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !r.Active) {
+		return nil, nil, errUnknownRejectionReason
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	reasonPtr = &r.Code
+	return reasonPtr, notePtr, nil
+}
+
+// parseRejection è resolveRejection per gli handler: in caso d'errore scrive
+// la risposta e restituisce false.
+func parseRejection(ctx *gin.Context, db DBTX, reason, note string) (*string, *string, bool) {
+	reasonPtr, notePtr, err := resolveRejection(db, reason, note)
+	switch {
+	case err == nil:
+		return reasonPtr, notePtr, true
+	case errors.Is(err, errRejectionReasonRequired):
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_rejection_reason",
+			Msg:    "A rejection reason is required (see /api/rejection-reasons)",
+		})
+	case errors.Is(err, errRejectionNoteTooLong):
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "rejection_note_too_long",
+			Msg:    "Rejection note must be at most " + strconv.Itoa(MAX_REJECTION_NOTE) + " characters",
+		})
+	case errors.Is(err, errUnknownRejectionReason):
+		ctx.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_rejection_reason",
+			Msg:    "Rejection reason " + strconv.Quote(strings.TrimSpace(reason)) + " does not exist or is not active",
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error checking rejection reason",
+		})
+	}
+	return nil, nil, false
+}
+
+func handleGetRejectionReasons(ctx *gin.Context) {
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	// ?all=true include anche i motivi disattivati
+	rows, err := QueryRejectionReasons(db, ctx.Query("all") == "true")
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying rejection reasons",
+		})
+		return
+	}
+	defer rows.Close()
+
+	reasons := []RejectionReason{}
+	for rows.Next() {
+		var r RejectionReason
+		if err := rows.Scan(&r.Code, &r.Label, &r.Description, &r.Active); err != nil {
+			continue
+		}
+		reasons = append(reasons, r)
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   reasons,
+	})
+}
+
+func handleAddRejectionReason(ctx *gin.Context) {
+	var req RejectionReasonRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	if !rejectionCodePattern.MatchString(req.Code) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_code",
+			Msg:    "Code must contain only lowercase letters, digits and underscores",
+		})
+		return
+	}
+
+	if strings.TrimSpace(req.Label) == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_label",
+			Msg:    "Rejection reason label is required",
+		})
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	if err := InsertRejectionReason(db, req.Code, strings.TrimSpace(req.Label), req.Description); err != nil {
+		respondDbError(ctx, err, "rejection_reason", "insert_error", "Error inserting rejection reason")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    "Rejection reason added successfully",
+	})
+}
+
+func handleUpdateRejectionReason(ctx *gin.Context) {
+	code := ctx.Param("code")
+
+	var req RejectionReasonRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	if strings.TrimSpace(req.Label) == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_label",
+			Msg:    "Rejection reason label is required",
+		})
+		return
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	if err := UpdateRejectionReason(db, code, strings.TrimSpace(req.Label), req.Description, active); err != nil {
+		respondDbError(ctx, err, "rejection_reason", "update_error", "Error updating rejection reason")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    "Rejection reason updated successfully",
+	})
+}
+
+// handleDeleteRejectionReason disattiva il motivo: i rifiuti già registrati
+// continuano a puntarlo, quindi non può essere eliminato fisicamente.
+func handleDeleteRejectionReason(ctx *gin.Context) {
+	code := ctx.Param("code")
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	if err := DeactivateRejectionReason(db, code); err != nil {
+		respondDbError(ctx, err, "rejection_reason", "delete_error", "Error deactivating rejection reason")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    "Rejection reason deactivated successfully",
+	})
+}
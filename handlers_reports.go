@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Soglie per segnalare un possibile abuso delle segnalazioni: più
+// segnalazioni dello stesso utente sullo stesso elemento, oppure troppe
+// segnalazioni in poco tempo su tutti i contenuti.
+const (
+	MASS_REPORT_WINDOW            = 24 * time.Hour
+	MASS_REPORT_THRESHOLD         = 20
+	REPEATED_REPORT_ON_ITEM_LIMIT = 2
+)
+
+var errNoOpenReports = errors.New("no open reports")
+
+// handleGetContentReports restituisce le singole segnalazioni di un elemento,
+// raggruppate per motivo e con il riepilogo dei segnalatori.
+func handleGetContentReports(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	if err := QueryContentExists(db, kind, id); err != nil {
+		respondDbError(ctx, err, kind.Name, "query_error", "Error querying "+kind.Name)
+		return
+	}
+
+	rows, err := QueryContentReports(db, kind, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying reports for " + kind.Name,
+		})
+		return
+	}
+	defer rows.Close()
+
+	result := ContentReports{
+		Kind:      kind.Name,
+		ItemID:    id,
+		Reports:   []ContentReport{},
+		ByReason:  []ReportReasonCount{},
+		Reporters: []ReporterSummary{},
+	}
+
+	reasonCounts := map[string]int{}
+	var noReason int
+	reporters := map[int]*ReporterSummary{}
+	for rows.Next() {
+		var r ContentReport
+		if err := rows.Scan(
+			&r.ID, &r.ReporterID, &r.ReporterFirstName, &r.ReporterLastName, &r.ReporterEmail,
+			&r.ReporterSchool, &r.CreatedAt, &r.Reason,
+			&r.ReviewedAt, &r.ReviewedByID, &r.ReviewedByName,
+		); err != nil {
+			println("Scan error (reports):", err.Error())
+			continue
+		}
+		result.Reports = append(result.Reports, r)
+		if r.ReviewedAt == nil {
+			result.Open++
+		}
+
+		if r.Reason != nil && strings.TrimSpace(*r.Reason) != "" {
+			reasonCounts[strings.TrimSpace(*r.Reason)]++
+		} else {
+			noReason++
+		}
+
+		summary, exists := reporters[r.ReporterID]
+		if !exists {
+			summary = &ReporterSummary{
+				ReporterID: r.ReporterID,
+				Name:       strings.TrimSpace(r.ReporterFirstName + " " + r.ReporterLastName),
+			}
+			reporters[r.ReporterID] = summary
+		}
+		summary.ReportsOnItem++
+	}
+	rows.Close()
+
+	result.Total = len(result.Reports)
+	result.DistinctReporters = len(reporters)
+
+	for reason, count := range reasonCounts {
+		reason := reason
+		result.ByReason = append(result.ByReason, ReportReasonCount{Reason: &reason, Count: count})
+	}
+	if noReason > 0 {
+		result.ByReason = append(result.ByReason, ReportReasonCount{Count: noReason})
+	}
+	sort.Slice(result.ByReason, func(i, j int) bool {
+		return result.ByReason[i].Count > result.ByReason[j].Count
+	})
+
+	if len(reporters) > 0 {
+		userIds := make([]int, 0, len(reporters))
+		for userId := range reporters {
+			userIds = append(userIds, userId)
+		}
+
+		// Senza i conteggi recenti restano comunque le ripetizioni sull'elemento
+		recent, err := QueryRecentReportCounts(db, userIds, MASS_REPORT_WINDOW)
+		if err != nil {
+			println("Recent report counts error:", err.Error())
+		}
+
+		for _, summary := range reporters {
+			summary.RecentReports = recent[summary.ReporterID]
+			summary.MassReporting = summary.ReportsOnItem >= REPEATED_REPORT_ON_ITEM_LIMIT ||
+				summary.RecentReports >= MASS_REPORT_THRESHOLD
+			if summary.MassReporting {
+				result.MassReporters++
+			}
+			result.Reporters = append(result.Reporters, *summary)
+		}
+		sort.Slice(result.Reporters, func(i, j int) bool {
+			a, b := result.Reporters[i], result.Reporters[j]
+			if a.MassReporting != b.MassReporting {
+				return a.MassReporting
+			}
+			if a.ReportsOnItem != b.ReportsOnItem {
+				return a.ReportsOnItem > b.ReportsOnItem
+			}
+			return a.RecentReports > b.RecentReports
+		})
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   result,
+	})
+}
+
+// auditedDismissReports archivia le segnalazioni aperte registrando quante erano
+func auditedDismissReports(db DBTX, entry AuditEntry, kind *ContentKind, id int) (int64, error) {
+	var dismissed int64
+	err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		if err := QueryContentExists(tx, kind, id); err != nil {
+			return nil, nil, err
+		}
+		var err error
+		dismissed, err = DismissReports(tx, kind, id, entry.ActorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if dismissed == 0 {
+			return nil, nil, errNoOpenReports
+		}
+		return map[string]any{"open_reports": dismissed}, map[string]any{"open_reports": 0}, nil
+	})
+	return dismissed, err
+}
+
+// handleDismissReports chiude le segnalazioni di un elemento senza cambiarne
+// lo stato: l'elemento esce dalla coda delle segnalazioni finché non ne
+// arrivano di nuove.
+func handleDismissReports(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	dismissed, err := auditedDismissReports(db, newAuditEntry(ctx, "dismiss_reports", kind.Name, id), kind, id)
+	if errors.Is(err, errNoOpenReports) {
+		ctx.JSON(http.StatusConflict, ErrorResponse{
+			Status: "error",
+			Error:  "no_open_reports",
+			Msg:    kind.Label + " has no open reports",
+		})
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "update_error", "Error dismissing reports for "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DismissResponse{
+		Status:    "ok",
+		Msg:       "Reports dismissed successfully",
+		Dismissed: dismissed,
+	})
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trimReason normalizza il motivo facoltativo di una modifica
+func trimReason(reason *string) *string {
+	if reason == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*reason)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// revisionParam legge il numero di revisione dalla route
+func revisionParam(ctx *gin.Context) (int, bool) {
+	revision, err := strconv.Atoi(ctx.Param("rev"))
+	if err != nil || revision < 1 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_revision",
+			Msg:    "Revision must be a positive integer",
+		})
+		return 0, false
+	}
+	return revision, true
+}
+
+// respondEditError traduce gli errori di modifica e ripristino del testo
+func respondEditError(ctx *gin.Context, kind *ContentKind, err error, errMsg string) {
+	if respondClaimConflict(ctx, kind, err) {
+		return
+	}
+	if errors.Is(err, errContentUnchanged) {
+		ctx.JSON(http.StatusConflict, ErrorResponse{
+			Status: "error",
+			Error:  "content_unchanged",
+			Msg:    "The new text is identical to the current one",
+		})
+		return
+	}
+	respondDbError(ctx, err, kind.Name, "update_error", errMsg)
+}
+
+// handleEditContent modifica il testo di un elemento (in qualsiasi stato),
+// ad esempio per togliere un cognome o un numero di telefono senza rifiutarlo
+func handleEditContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	var req EditContentRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_content",
+			Msg:    "Content is required",
+		})
+		return
+	}
+
+	entry := newAuditEntry(ctx, "edit_content", kind.Name, id)
+	revision, err := auditedEditContent(db, entry, kind, id, content, trimReason(req.Reason), nil)
+	if err != nil {
+		respondEditError(ctx, kind, err, "Error editing "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RevisionResponse{
+		Status:   "ok",
+		Msg:      kind.Label + " edited successfully",
+		Revision: revision,
+	})
+}
+
+// handleGetRevisions restituisce la cronologia del testo, ognuna con il diff
+// rispetto alla precedente. Un elemento mai modificato non ha revisioni.
+func handleGetRevisions(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	if err := QueryContentExists(db, kind, id); err != nil {
+		respondDbError(ctx, err, kind.Name, "query_error", "Error querying "+kind.Name)
+		return
+	}
+
+	revisions, err := QueryRevisions(db, kind, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying revisions for " + kind.Name,
+		})
+		return
+	}
+	if revisions == nil {
+		revisions = []ContentRevision{}
+	}
+	for i := 1; i < len(revisions); i++ {
+		revisions[i].Diff = diffText(revisions[i-1].Content, revisions[i].Content)
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   revisions,
+	})
+}
+
+// handleGetRevisionDiff confronta la revisione :rev con ?from= (di default
+// la precedente)
+func handleGetRevisionDiff(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	to, ok := revisionParam(ctx)
+	if !ok {
+		return
+	}
+	from := to - 1
+	if v := ctx.Query("from"); v != "" {
+		var err error
+		if from, err = strconv.Atoi(v); err != nil || from < 1 {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_revision",
+				Msg:    "from must be a positive integer",
+			})
+			return
+		}
+	}
+	if from < 1 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_revision",
+			Msg:    "Revision 1 is the original text: specify ?from= to compare it",
+		})
+		return
+	}
+
+	toRevision, err := QueryRevision(db, kind, id, to)
+	if err != nil {
+		respondDbError(ctx, err, "revision", "query_error", "Error querying revision")
+		return
+	}
+	fromRevision, err := QueryRevision(db, kind, id, from)
+	if err != nil {
+		respondDbError(ctx, err, "revision", "query_error", "Error querying revision")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RevisionDiffResponse{
+		Status: "ok",
+		From:   from,
+		To:     to,
+		Diff:   diffText(fromRevision.Content, toRevision.Content),
+	})
+}
+
+// handleRevertContent ripristina il testo di una revisione precedente,
+// registrandolo come nuova revisione (la cronologia non si riscrive)
+func handleRevertContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	revision, ok := revisionParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req RevertContentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	target, err := QueryRevision(db, kind, id, revision)
+	if err != nil {
+		respondDbError(ctx, err, "revision", "query_error", "Error querying revision")
+		return
+	}
+
+	entry := newAuditEntry(ctx, "revert_content", kind.Name, id)
+	newRevision, err := auditedEditContent(db, entry, kind, id, target.Content, trimReason(req.Reason), &revision)
+	if err != nil {
+		respondEditError(ctx, kind, err, "Error reverting "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RevisionResponse{
+		Status:   "ok",
+		Msg:      kind.Label + " reverted to revision " + strconv.Itoa(revision),
+		Revision: newRevision,
+	})
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userIdParam legge l'ID utente dalla route
+func userIdParam(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_id",
+			Msg:    "Invalid user ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// sanctionExpiry calcola la scadenza richiesta; nil se non indicata
+func sanctionExpiry(ctx *gin.Context, req SanctionRequest) (*time.Time, bool) {
+	if req.DurationHours != 0 && req.ExpiresAt != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_expiry",
+			Msg:    "Provide either duration_hours or expires_at, not both",
+		})
+		return nil, false
+	}
+	var expiresAt *time.Time
+	if req.DurationHours != 0 {
+		if req.DurationHours < 1 || req.DurationHours > MAX_SANCTION_HOURS {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_duration",
+				Msg:    "duration_hours must be between 1 and " + strconv.Itoa(MAX_SANCTION_HOURS),
+			})
+			return nil, false
+		}
+		t := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+		expiresAt = &t
+	} else if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_expiry",
+				Msg:    "expires_at must be in the future",
+			})
+			return nil, false
+		}
+		expiresAt = req.ExpiresAt
+	}
+
+	if expiresAt != nil && !expiringSanctionTypes[req.Type] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_expiry",
+			Msg:    "Only suspension and shadow_ban sanctions can expire",
+		})
+		return nil, false
+	}
+	if expiresAt == nil && req.Type == "suspension" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_expiry",
+			Msg:    "A suspension requires duration_hours or expires_at",
+		})
+		return nil, false
+	}
+	return expiresAt, true
+}
+
+func handleGetUserSanctions(ctx *gin.Context) {
+	userId, ok := userIdParam(ctx)
+	if !ok {
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	sanctions, err := QueryUserSanctions(db, userId)
+	if err != nil {
+		respondDbError(ctx, err, "user", "query_error", "Error querying sanctions")
+		return
+	}
+	if sanctions.Sanctions == nil {
+		sanctions.Sanctions = []UserSanction{}
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   sanctions,
+	})
+}
+
+// handleAddUserSanction applica una sanzione; con reject_pending rifiuta
+// anche gli elementi in attesa dell'utente (solo con accesso completo)
+func handleAddUserSanction(ctx *gin.Context) {
+	userId, ok := userIdParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req SanctionRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	if !validSanctionTypes[req.Type] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_type",
+			Msg:    "Type must be: warning, suspension, ban, or shadow_ban",
+		})
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_reason",
+			Msg:    "A reason is required",
+		})
+		return
+	}
+	if len([]rune(req.Reason)) > MAX_SANCTION_REASON {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "reason_too_long",
+			Msg:    "Reason must be at most " + strconv.Itoa(MAX_SANCTION_REASON) + " characters",
+		})
+		return
+	}
+
+	expiresAt, ok := sanctionExpiry(ctx, req)
+	if !ok {
+		return
+	}
+
+	if req.RejectPending {
+		if req.Type == "warning" {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_reject_pending",
+				Msg:    "reject_pending is not allowed with a warning",
+			})
+			return
+		}
+		// Rifiutare contenuti richiede i permessi della moderazione
+		if ctx.GetString("role") == ROLE_USERS_ONLY {
+			ctx.JSON(http.StatusForbidden, ErrorResponse{
+				Status: "error",
+				Error:  "access_denied",
+				Msg:    "Non hai i permessi per rifiutare i contenuti",
+			})
+			return
+		}
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	var reason, note *string
+	if req.RejectPending {
+		if reason, note, ok = parseRejection(ctx, db, req.RejectionReason, req.RejectionNote); !ok {
+			return
+		}
+	}
+
+	if err := QueryUserExists(db, userId); err != nil {
+		respondDbError(ctx, err, "user", "query_error", "Error querying user")
+		return
+	}
+
+	sanction, err := auditedSanction(db, newAuditEntry(ctx, "add_sanction", "user", userId), userId, req, expiresAt)
+	if err != nil {
+		respondDbError(ctx, err, "user", "update_error", "Error adding sanction")
+		return
+	}
+
+	result := SanctionResult{Sanction: sanction}
+	if req.RejectPending {
+		// La sanzione resta anche se qualche rifiuto non va a buon fine
+		result.RejectedPending, result.RejectFailures, err = rejectPendingFromUser(ctx, db, userId, reason, note)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+				Status: "error",
+				Error:  "query_error",
+				Msg:    "Sanction added, but pending content could not be selected",
+			})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   result,
+	})
+}
+
+func handleLiftUserSanction(ctx *gin.Context) {
+	userId, ok := userIdParam(ctx)
+	if !ok {
+		return
+	}
+	sanctionId, err := strconv.Atoi(ctx.Param("sanction_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_sanction_id",
+			Msg:    "Invalid sanction ID",
+		})
+		return
+	}
+
+	// Il corpo è facoltativo: il motivo della revoca non è obbligatorio
+	var req LiftSanctionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+	reason := trimReason(&req.Reason)
+	if reason != nil && len([]rune(*reason)) > MAX_SANCTION_REASON {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "reason_too_long",
+			Msg:    "Reason must be at most " + strconv.Itoa(MAX_SANCTION_REASON) + " characters",
+		})
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	sanction, err := auditedLiftSanction(db, newAuditEntry(ctx, "lift_sanction", "user", userId), userId, sanctionId, reason)
+	if errors.Is(err, errSanctionNotActive) {
+		ctx.JSON(http.StatusConflict, ErrorResponse{
+			Status: "error",
+			Error:  "sanction_not_active",
+			Msg:    "Sanction is a warning or has already been lifted",
+		})
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, "sanction", "update_error", "Error lifting sanction")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   sanction,
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// scheduleContent programma la pubblicazione di un elemento in attesa e
+// risponde; con reschedule l'elemento deve essere già programmato
+func scheduleContent(ctx *gin.Context, db *pgx.Conn, kind *ContentKind, id int, publishAt time.Time, reschedule bool) {
+	if !validatePublishAt(ctx, publishAt) {
+		return
+	}
+
+	action := "schedule_publish"
+	if reschedule {
+		action = "reschedule_publish"
+	}
+	err := auditedSchedule(db, newAuditEntry(ctx, action, kind.Name, id), kind, id, &publishAt, reschedule)
+	if respondScheduleError(ctx, kind, err) {
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "update_error", "Error scheduling "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ScheduleResponse{
+		Status:    "ok",
+		Msg:       kind.Label + " scheduled for publishing",
+		PublishAt: &publishAt,
+	})
+}
+
+// handleGetScheduledContent elenca gli elementi approvati con pubblicazione
+// programmata, dal più vicino
+func handleGetScheduledContent(ctx *gin.Context) {
+	listContent(ctx, makeDbaseConnection, QUEUE_SCHEDULED, "publish_at", "Error querying scheduled")
+}
+
+// handleRescheduleContent sposta la pubblicazione di un elemento programmato
+func handleRescheduleContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	var req ScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+	if req.PublishAt == nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_publish_at",
+			Msg:    "publish_at is required",
+		})
+		return
+	}
+
+	scheduleContent(ctx, db, kind, id, *req.PublishAt, true)
+}
+
+// handleCancelSchedule annulla la pubblicazione programmata: l'elemento
+// torna nella coda in attesa
+func handleCancelSchedule(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	err := auditedSchedule(db, newAuditEntry(ctx, "cancel_schedule", kind.Name, id), kind, id, nil, true)
+	if respondScheduleError(ctx, kind, err) {
+		return
+	}
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "update_error", "Error cancelling schedule")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ScheduleResponse{
+		Status: "ok",
+		Msg:    "Schedule cancelled, " + kind.Name + " is back in the pending queue",
+	})
+}
@@ -120,11 +120,7 @@ func handleAddSchool(ctx *gin.Context) {
 	defer db.Close(ctx)
 
 	if err := InsertSchool(db, req.Name, req.CityID, req.EmailDomain); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "insert_error",
-			Msg:    "Error inserting school: " + err.Error(),
-		})
+		respondDbError(ctx, err, "school", "insert_error", "Error inserting school")
 		return
 	}
 
@@ -168,11 +164,7 @@ func handleUpdateSchool(ctx *gin.Context) {
 	defer db.Close(ctx)
 
 	if err := UpdateSchool(db, id, req.Name, req.EmailDomain); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "update_error",
-			Msg:    "Error updating school",
-		})
+		respondDbError(ctx, err, "school", "update_error", "Error updating school")
 		return
 	}
 
@@ -206,11 +198,7 @@ func handleDeleteSchool(ctx *gin.Context) {
 	defer db.Close(ctx)
 
 	if err := DeleteSchool(db, id); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "delete_error",
-			Msg:    "Error deleting school. Make sure no users reference this school.",
-		})
+		respondDbError(ctx, err, "school", "delete_error", "Error deleting school")
 		return
 	}
 
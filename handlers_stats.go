@@ -5,10 +5,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 func handleGetStatistics(ctx *gin.Context) {
-	db, err := makeDbaseConnection()
+	db, err := makeReadConnection()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
 			Status: "error",
@@ -19,10 +20,7 @@ func handleGetStatistics(ctx *gin.Context) {
 	}
 	defer db.Close(ctx)
 
-	var stats FullStatistics
-
-	// 1. Get total stats
-	rows, err := QueryTotalStats(db)
+	stats, err := collectStatistics(db)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
 			Status: "error",
@@ -31,6 +29,23 @@ func handleGetStatistics(ctx *gin.Context) {
 		})
 		return
 	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   stats,
+	})
+}
+
+// collectStatistics raccoglie tutte le statistiche; condivisa da API e CLI.
+// Solo i totali sono obbligatori, le altre sezioni restano vuote in caso di errore.
+func collectStatistics(db *pgx.Conn) (FullStatistics, error) {
+	var stats FullStatistics
+
+	// 1. Get total stats
+	rows, err := QueryTotalStats(db)
+	if err != nil {
+		return stats, err
+	}
 	if rows.Next() {
 		rows.Scan(
 			&stats.Totals.TotalUsers,
@@ -130,6 +145,17 @@ func handleGetStatistics(ctx *gin.Context) {
 		rows.Close()
 	}
 
+	// 9. Get rejections by reason
+	rows, err = QueryRejectionStats(db)
+	if err == nil {
+		for rows.Next() {
+			var reason RejectionReasonStats
+			rows.Scan(&reason.Code, &reason.Label, &reason.PostCount, &reason.SpottedCount)
+			stats.RejectionReasons = append(stats.RejectionReasons, reason)
+		}
+		rows.Close()
+	}
+
 	// Initialize empty slices if nil
 	if stats.CitiesStats == nil {
 		stats.CitiesStats = []CityStats{}
@@ -152,9 +178,9 @@ func handleGetStatistics(ctx *gin.Context) {
 	if stats.TopSchools == nil {
 		stats.TopSchools = []TopSchool{}
 	}
+	if stats.RejectionReasons == nil {
+		stats.RejectionReasons = []RejectionReasonStats{}
+	}
 
-	ctx.JSON(http.StatusOK, DataResponse{
-		Status: "ok",
-		Data:   stats,
-	})
+	return stats, nil
 }
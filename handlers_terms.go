@@ -0,0 +1,286 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validateBannedTerm normalizza e controlla la richiesta; in caso d'errore
+// scrive la risposta e restituisce false.
+func validateBannedTerm(ctx *gin.Context, req *BannedTermRequest) bool {
+	req.Pattern = strings.TrimSpace(req.Pattern)
+	if req.Severity == "" {
+		req.Severity = "medium"
+	}
+	if req.Action == "" {
+		req.Action = "flag"
+	}
+
+	if req.Pattern == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "missing_pattern",
+			Msg:    "Pattern is required",
+		})
+		return false
+	}
+
+	if !validTermSeverities[req.Severity] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_severity",
+			Msg:    "Severity must be: low, medium, or high",
+		})
+		return false
+	}
+
+	if !validTermActions[req.Action] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_action",
+			Msg:    "Action must be: flag or auto_reject",
+		})
+		return false
+	}
+
+	if req.IsRegex {
+		re, err := regexp.Compile(req.Pattern)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_regex",
+				Msg:    "Invalid regular expression: " + err.Error(),
+			})
+			return false
+		}
+		// Una regex che accetta la stringa vuota segnalerebbe qualsiasi testo
+		if re.MatchString("") {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_regex",
+				Msg:    "Regular expression must not match the empty string",
+			})
+			return false
+		}
+	} else if len(normalizeTerm(req.Pattern)) == 0 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_pattern",
+			Msg:    "Pattern must contain at least one letter or digit",
+		})
+		return false
+	}
+
+	if req.RejectionReason != nil && *req.RejectionReason == "" {
+		req.RejectionReason = nil
+	}
+	return true
+}
+
+func handleGetBannedTerms(ctx *gin.Context) {
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	terms, err := QueryBannedTerms(db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying banned terms",
+		})
+		return
+	}
+	if terms == nil {
+		terms = []BannedTerm{}
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   terms,
+	})
+}
+
+func handleAddBannedTerm(ctx *gin.Context) {
+	var req BannedTermRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	if !validateBannedTerm(ctx, &req) {
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	var createdBy *int
+	if moderatorId := ctx.GetInt("moderator_id"); moderatorId > 0 {
+		createdBy = &moderatorId
+	}
+
+	if err := InsertBannedTerm(db, req, createdBy); err != nil {
+		respondDbError(ctx, err, "banned_term", "insert_error", "Error inserting banned term")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    "Banned term added successfully",
+	})
+}
+
+func handleUpdateBannedTerm(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_id",
+			Msg:    "Invalid banned term ID",
+		})
+		return
+	}
+
+	var req BannedTermRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	if !validateBannedTerm(ctx, &req) {
+		return
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	if err := UpdateBannedTerm(db, id, req, active); err != nil {
+		respondDbError(ctx, err, "banned_term", "update_error", "Error updating banned term")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    "Banned term updated successfully",
+	})
+}
+
+func handleDeleteBannedTerm(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_id",
+			Msg:    "Invalid banned term ID",
+		})
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	if err := DeleteBannedTerm(db, id); err != nil {
+		respondDbError(ctx, err, "banned_term", "delete_error", "Error deleting banned term")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse{
+		Status: "ok",
+		Msg:    "Banned term deleted successfully",
+	})
+}
+
+// handleTestBannedTerms mostra quali termini attivi scatterebbero su un testo
+func handleTestBannedTerms(ctx *gin.Context) {
+	var req TermTestRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "malformed_json",
+			Msg:    "Invalid request body",
+		})
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	matcher, err := loadTermMatcher(db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying banned terms",
+		})
+		return
+	}
+
+	matches := matcher.Match(req.Text)
+	if matches == nil {
+		matches = []TermMatch{}
+	}
+
+	ctx.JSON(http.StatusOK, DataResponse{
+		Status: "ok",
+		Data:   matches,
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func handleGetTrash(ctx *gin.Context) {
+	kind, ok := resolveContentKind(ctx)
+	if !ok {
+		return
+	}
+
+	page, pageSize, ok := parsePagination(ctx)
+	if !ok {
+		return
+	}
+
+	db, err := makeDbaseConnection()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "database_unreachable",
+			Msg:    "Cannot connect to database",
+		})
+		return
+	}
+	defer db.Close(ctx)
+
+	total, err := CountTrash(db, kind)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying trash",
+		})
+		return
+	}
+
+	rows, err := QueryTrash(db, kind, pageSize, (page-1)*pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status: "error",
+			Error:  "query_error",
+			Msg:    "Error querying trash",
+		})
+		return
+	}
+	defer rows.Close()
+
+	retention := trashRetention()
+	items := []TrashItem{}
+	for rows.Next() {
+		item := TrashItem{Kind: kind.Name}
+		if err := rows.Scan(
+			&item.ID, &item.Content, &item.CreatorID, &item.CreatorFirstName, &item.CreatorLastName,
+			&item.CreationTimestamp, &item.LikesCount, &item.ReportCount,
+			&item.DeletedAt, &item.DeletedByID, &item.DeletedByName,
+		); err != nil {
+			println("Scan error (trash):", err.Error())
+			continue
+		}
+		item.PurgeAt = item.DeletedAt.Add(retention)
+		items = append(items, item)
+	}
+
+	ctx.JSON(http.StatusOK, PagedResponse{
+		Status:   "ok",
+		Data:     items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+func handleRestoreContent(ctx *gin.Context) {
+	kind, id, db, ok := contentActionTarget(ctx)
+	if !ok {
+		return
+	}
+	defer db.Close(ctx)
+
+	result, err := auditedRestore(db, newAuditEntry(ctx, "restore", kind.Name, id), kind, id)
+	if err != nil {
+		respondDbError(ctx, err, kind.Name, "restore_error", "Error restoring "+kind.Name)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, RestoreResponse{
+		Status:        "ok",
+		Msg:           kind.Label + " restored successfully",
+		RestoreResult: result,
+	})
+}
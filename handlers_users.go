@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 func handleSearchUsers(ctx *gin.Context) {
@@ -64,6 +65,9 @@ func handleSearchUsers(ctx *gin.Context) {
 			&user.Role,
 			&user.SchoolName,
 			&user.CityName,
+			&user.SuspendedUntil,
+			&user.BannedAt,
+			&user.ShadowBannedAt,
 		); err == nil {
 			users = append(users, user)
 		}
@@ -97,9 +101,8 @@ func handleSetUserRole(ctx *gin.Context) {
 		return
 	}
 
-	// Validate role - only allow user and representative for now
-	validRoles := map[string]bool{"user": true, "representative": true}
-	if !validRoles[req.Role] {
+	// Validate role
+	if !validUserRoles[req.Role] {
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{
 			Status: "error",
 			Error:  "invalid_role",
@@ -119,12 +122,18 @@ func handleSetUserRole(ctx *gin.Context) {
 	}
 	defer db.Close(ctx)
 
-	if err := SetUserRole(db, id, req.Role); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "update_error",
-			Msg:    "Error updating user role",
-		})
+	err = runAudited(db, newAuditEntry(ctx, "set_role", "user", id), func(tx pgx.Tx) (any, any, error) {
+		before, err := QueryUserRole(tx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := SetUserRole(tx, id, req.Role); err != nil {
+			return nil, nil, err
+		}
+		return map[string]any{"role": before}, map[string]any{"role": req.Role}, nil
+	})
+	if err != nil {
+		respondDbError(ctx, err, "user", "update_error", "Error updating user role")
 		return
 	}
 
@@ -157,37 +166,9 @@ func handleGetUser(ctx *gin.Context) {
 	}
 	defer db.Close(ctx)
 
-	rows, err := GetUserById(db, id)
+	user, err := QueryUser(db, id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "query_error",
-			Msg:    "Error getting user",
-		})
-		return
-	}
-	defer rows.Close()
-
-	if !rows.Next() {
-		ctx.JSON(http.StatusNotFound, ErrorResponse{
-			Status: "error",
-			Error:  "user_not_found",
-			Msg:    "User not found",
-		})
-		return
-	}
-
-	var user User
-	if err := rows.Scan(
-		&user.ID, &user.Email, &user.PersonalEmail,
-		&user.FirstName, &user.LastName, &user.Role,
-		&user.SchoolName, &user.CityName,
-	); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Status: "error",
-			Error:  "scan_error",
-			Msg:    "Error reading user data",
-		})
+		respondDbError(ctx, err, "user", "query_error", "Error getting user")
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
@@ -21,11 +22,22 @@ func makeDbaseConnection() (*pgx.Conn, error) {
 	return pgx.Connect(context.Background(), connStr)
 }
 
+// makeReadConnection restituisce una connessione per le sole letture pesanti:
+// la replica se configurata e allineata, altrimenti il primario.
+// Non usarla mai per query che modificano dati.
+func makeReadConnection() (*pgx.Conn, error) {
+	if db := replicaConnection(); db != nil {
+		return db, nil
+	}
+	return makeDbaseConnection()
+}
+
 func corsMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		ctx.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Last-Event-ID")
+		ctx.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if ctx.Request.Method == "OPTIONS" {
 			ctx.AbortWithStatus(http.StatusNoContent)
@@ -37,10 +49,41 @@ func corsMiddleware() gin.HandlerFunc {
 }
 
 func main() {
+	if err := loadConfig(CONFIG_PATH); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Nessun argomento: avvio del server (retrocompatibile)
+	if len(os.Args) < 2 {
+		runServer()
+		return
+	}
+	os.Exit(runCommand(os.Args[1], os.Args[2:]))
+}
+
+func runServer() {
 	router := gin.Default()
+	// ClientIP finisce nel registro di audit: X-Forwarded-For vale solo se
+	// arriva da un proxy configurato, altrimenti si usa l'indirizzo remoto
+	if err := router.SetTrustedProxies(CONF.TRUSTED_PROXIES); err != nil {
+		println("Invalid trusted_proxies:", err.Error())
+		os.Exit(1)
+	}
+
+	startTrashPurger()
+	startTermScanner()
+	startAutoHider()
+	startPIIScanner()
+	startMentionScanner()
+	startFingerprinter()
+	startSanctionExpirer()
+	startPublishScheduler()
+	EVENT_HUB.start()
 
 	// Enable CORS
 	router.Use(corsMiddleware())
+	router.Use(requestIdMiddleware())
 
 	// Serve static files (dashboard)
 	router.Static("/dashboard", "./dashboard")
@@ -63,6 +106,9 @@ func main() {
 			protected.GET("/users/search", handleSearchUsers)
 			protected.GET("/users/:id", handleGetUser)
 			protected.PUT("/users/:id/role", handleSetUserRole)
+			protected.GET("/users/:id/sanctions", handleGetUserSanctions)
+			protected.POST("/users/:id/sanctions", handleAddUserSanction)
+			protected.DELETE("/users/:id/sanctions/:sanction_id", handleLiftUserSanction)
 		}
 
 		// Full access routes (blocked for users_only role)
@@ -73,6 +119,28 @@ func main() {
 			// Statistics
 			fullAccess.GET("/statistics", handleGetStatistics)
 
+			// Audit log
+			fullAccess.GET("/audit", handleGetAudit)
+
+			// Profilo di moderazione degli utenti
+			fullAccess.GET("/users/:id/profile", handleGetUserProfile)
+
+			// Rejection reasons catalogue
+			fullAccess.GET("/rejection-reasons", handleGetRejectionReasons)
+			fullAccess.POST("/rejection-reasons", handleAddRejectionReason)
+			fullAccess.PUT("/rejection-reasons/:code", handleUpdateRejectionReason)
+			fullAccess.DELETE("/rejection-reasons/:code", handleDeleteRejectionReason)
+
+			// Eventi in tempo reale (SSE)
+			fullAccess.GET("/events", handleEventStream)
+
+			// Banned terms
+			fullAccess.GET("/banned-terms", handleGetBannedTerms)
+			fullAccess.POST("/banned-terms", handleAddBannedTerm)
+			fullAccess.POST("/banned-terms/test", handleTestBannedTerms)
+			fullAccess.PUT("/banned-terms/:id", handleUpdateBannedTerm)
+			fullAccess.DELETE("/banned-terms/:id", handleDeleteBannedTerm)
+
 			// Cities CRUD
 			fullAccess.GET("/cities", handleGetCities)
 			fullAccess.POST("/cities", handleAddCity)
@@ -85,23 +153,12 @@ func main() {
 			fullAccess.PUT("/schools/:id", handleUpdateSchool)
 			fullAccess.DELETE("/schools/:id", handleDeleteSchool)
 
-			// Posts moderation
-			fullAccess.GET("/posts", handleGetAllPosts)
-			fullAccess.GET("/posts/pending", handleGetPendingPosts)
-			fullAccess.GET("/posts/reported", handleGetReportedPosts)
-			fullAccess.PUT("/posts/:id/approve", handleApprovePost)
-			fullAccess.PUT("/posts/:id/reject", handleRejectPost)
-			fullAccess.PUT("/posts/:id/status", handleSetPostStatus)
-			fullAccess.DELETE("/posts/:id", handleDeletePost)
-
-			// Spotted moderation
-			fullAccess.GET("/spotted", handleGetAllSpotted)
-			fullAccess.GET("/spotted/pending", handleGetPendingSpotted)
-			fullAccess.GET("/spotted/reported", handleGetReportedSpotted)
-			fullAccess.PUT("/spotted/:id/approve", handleApproveSpotted)
-			fullAccess.PUT("/spotted/:id/reject", handleRejectSpotted)
-			fullAccess.PUT("/spotted/:id/status", handleSetSpottedStatus)
-			fullAccess.DELETE("/spotted/:id", handleDeleteSpotted)
+			// Content moderation: /content/:kind/... per ogni tipo registrato
+			registerContentRoutes(fullAccess, "/content/:kind", "")
+
+			// Alias storici per post e spotted
+			registerContentRoutes(fullAccess, "/posts", POST_KIND.Name)
+			registerContentRoutes(fullAccess, "/spotted", SPOTTED_KIND.Name)
 		}
 	}
 
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	MENTION_SCAN_INTERVAL = time.Minute
+	MENTION_SCAN_BATCH    = 500
+)
+
+// studentPatterns sono nome+cognome e cognome+nome di uno studente, nella
+// forma normalizzata usata anche per i termini vietati (maiuscole, accenti,
+// leetspeak, lettere ripetute e spaziature non contano)
+type studentPatterns struct {
+	SchoolStudent
+	patterns [][]rune
+}
+
+func compileStudents(students []SchoolStudent) []studentPatterns {
+	compiled := make([]studentPatterns, 0, len(students))
+	for _, s := range students {
+		firstLast := normalizeTerm(s.FirstName + " " + s.LastName)
+		lastFirst := normalizeTerm(s.LastName + " " + s.FirstName)
+		if len(firstLast) == 0 {
+			continue
+		}
+		compiled = append(compiled, studentPatterns{SchoolStudent: s, patterns: [][]rune{firstLast, lastFirst}})
+	}
+	return compiled
+}
+
+// findMentions cerca nel testo le coppie nome e cognome degli studenti, in
+// entrambi gli ordini. Il solo nome o il solo cognome non bastano.
+func findMentions(text string, students []studentPatterns, excludeId int) []StudentMention {
+	nodes := normalizeText(text)
+	if len(nodes) == 0 {
+		return nil
+	}
+	original := []rune(text)
+
+	type key struct{ user, start int }
+	seen := map[key]bool{}
+	var mentions []StudentMention
+	for _, s := range students {
+		// Chi scrive di sé stesso non è un caso di bullismo
+		if s.ID == excludeId {
+			continue
+		}
+		for _, pattern := range s.patterns {
+			for i := 0; i < len(nodes); i++ {
+				if !nodes[i].wordStart {
+					continue
+				}
+				last := matchAt(nodes, i, pattern)
+				if last < 0 || !nodes[last].wordEnd || !spansWords(nodes, i, last) {
+					continue
+				}
+				start, end := nodes[i].start, nodes[last].end
+				if seen[key{s.ID, start}] {
+					continue
+				}
+				seen[key{s.ID, start}] = true
+				mentions = append(mentions, StudentMention{
+					UserID:    s.ID,
+					FirstName: s.FirstName,
+					LastName:  s.LastName,
+					Start:     start,
+					End:       end,
+					Text:      string(original[start:end]),
+				})
+				i = last
+			}
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].Start < mentions[j].Start })
+	return mentions
+}
+
+// spansWords è vero se i nodi da first a last coprono più di una parola:
+// "Al Ice" non deve riconoscere "alice"
+func spansWords(nodes []normNode, first, last int) bool {
+	for k := first + 1; k <= last; k++ {
+		if nodes[k].wordStart {
+			return true
+		}
+	}
+	return false
+}
+
+// annotateMentions allega agli elementi di una lista le menzioni salvate
+func annotateMentions(db DBTX, kind *ContentKind, items []ContentItem) {
+	var ids []int
+	for _, item := range items {
+		if item.MentionedStudents > 0 {
+			ids = append(ids, item.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	mentions, err := QueryContentMentions(db, kind, ids)
+	if err != nil {
+		println("Mentions error ("+kind.Name+"):", err.Error())
+		return
+	}
+	for i := range items {
+		items[i].Mentions = mentions[items[i].ID]
+	}
+}
+
+// flagMentionedStudents controlla gli elementi in attesa non ancora esaminati
+// contro gli studenti della scuola dell'autore e salva le menzioni trovate.
+// Gli elementi con menzioni ricevono una voce di audit di sistema.
+// Restituisce quanti elementi menzionano almeno uno studente.
+func flagMentionedStudents(db *pgx.Conn) (int, error) {
+	flagged := 0
+	schools := map[int][]studentPatterns{}
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		candidates, err := QueryMentionUnchecked(db, kind, MENTION_SCAN_BATCH)
+		if err != nil {
+			return flagged, err
+		}
+		for _, c := range candidates {
+			var mentions []StudentMention
+			if c.SchoolID != nil {
+				students, ok := schools[*c.SchoolID]
+				if !ok {
+					list, err := QuerySchoolStudents(db, *c.SchoolID)
+					if err != nil {
+						return flagged, err
+					}
+					students = compileStudents(list)
+					schools[*c.SchoolID] = students
+				}
+				mentions = findMentions(c.Content, students, c.CreatorID)
+			}
+
+			if len(mentions) == 0 {
+				if err := SaveMentions(db, kind, c.ID, nil); err != nil {
+					return flagged, err
+				}
+				continue
+			}
+
+			var userIds []int
+			for _, m := range mentions {
+				userIds = append(userIds, m.UserID)
+			}
+			entry := AuditEntry{
+				Action:     "mention_flag",
+				TargetType: kind.Name,
+				TargetID:   c.ID,
+				ActorRole:  ACTOR_SYSTEM,
+			}
+			err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+				if err := SaveMentions(tx, kind, c.ID, mentions); err != nil {
+					return nil, nil, err
+				}
+				return nil, map[string]any{"mentioned_users": userIds}, nil
+			})
+			if err != nil {
+				println("Mention flag error ("+kind.Name+"):", err.Error())
+				continue
+			}
+			flagged++
+		}
+	}
+	return flagged, nil
+}
+
+// startMentionScanner avvia in background il controllo delle menzioni
+func startMentionScanner() {
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("Mention scan: cannot connect to database:", err.Error())
+			} else {
+				count, err := flagMentionedStudents(db)
+				if err != nil {
+					println("Mention scan error:", err.Error())
+				} else if count > 0 {
+					println("Mention scan: flagged", count, "items")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(MENTION_SCAN_INTERVAL)
+		}
+	}()
+}
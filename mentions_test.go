@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestFindMentions(t *testing.T) {
+	students := compileStudents([]SchoolStudent{
+		{ID: 1, FirstName: "Mario", LastName: "Rossi"},
+		{ID: 2, FirstName: "Niccolò", LastName: "De Luca"},
+		{ID: 3, FirstName: "", LastName: ""},
+	})
+	if len(students) != 2 {
+		t.Fatalf("studenti compilati %d, attesi 2", len(students))
+	}
+
+	type mention struct {
+		user, start, end int
+		text             string
+	}
+	tests := []struct {
+		name      string
+		text      string
+		excludeId int
+		want      []mention
+	}{
+		{"nessuno", "ciao a tutti", 0, nil},
+		{"nome e cognome", "ho visto Mario Rossi", 0, []mention{{1, 9, 20, "Mario Rossi"}}},
+		{"cognome e nome", "rossi mario è simpatico", 0, []mention{{1, 0, 11, "rossi mario"}}},
+		{"solo il nome", "Mario è simpatico", 0, nil},
+		{"solo il cognome", "la famiglia Rossi", 0, nil},
+		{"attaccati", "mariorossi", 0, nil},
+		{"dentro un'altra parola", "supermario rossi", 0, nil},
+		{"evasione", "M4R1O   R0SSSI", 0, []mention{{1, 0, 14, "M4R1O   R0SSSI"}}},
+		{"accenti e cognome composto", "niccolo de luca", 0, []mention{{2, 0, 15, "niccolo de luca"}}},
+		{"autore escluso", "sono Mario Rossi", 1, nil},
+		{"più studenti in ordine", "De Luca Niccolò e Mario Rossi", 0, []mention{
+			{2, 0, 15, "De Luca Niccolò"},
+			{1, 18, 29, "Mario Rossi"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findMentions(tt.text, students, tt.excludeId)
+			if len(got) != len(tt.want) {
+				t.Fatalf("findMentions(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.UserID != w.user || g.Start != w.start || g.End != w.end || g.Text != w.text {
+					t.Errorf("menzione %d = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
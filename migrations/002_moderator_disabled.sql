@@ -0,0 +1,4 @@
+-- Migration: Allow disabling moderators
+-- Disabled moderators cannot log in (see `moderator disable` CLI command)
+
+ALTER TABLE moderators ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,33 @@
+-- Migration: Moderation audit log
+-- Append-only record of every moderation action, written in the same
+-- transaction as the change it describes.
+
+CREATE TABLE IF NOT EXISTS moderation_audit (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    actor_id INTEGER,              -- moderators.id, NULL for CLI and static accounts
+    actor_role TEXT NOT NULL,      -- full, users_only, cli, system
+    action TEXT NOT NULL,          -- approve, reject, set_status, delete, set_role, ...
+    target_type TEXT NOT NULL,     -- post, spotted, user, ...
+    target_id INTEGER NOT NULL,
+    before_value JSONB,
+    after_value JSONB,
+    ip TEXT,
+    request_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS moderation_audit_created_idx ON moderation_audit (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS moderation_audit_target_idx ON moderation_audit (target_type, target_id);
+CREATE INDEX IF NOT EXISTS moderation_audit_actor_idx ON moderation_audit (actor_id);
+
+-- Append-only: reject any UPDATE or DELETE on the audit table
+CREATE OR REPLACE FUNCTION moderation_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'moderation_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS moderation_audit_no_change ON moderation_audit;
+CREATE TRIGGER moderation_audit_no_change
+    BEFORE UPDATE OR DELETE ON moderation_audit
+    FOR EACH ROW EXECUTE FUNCTION moderation_audit_append_only();
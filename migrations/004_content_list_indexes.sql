@@ -0,0 +1,12 @@
+-- Migration: Indexes for paginated content lists
+-- Keyset pagination walks (creation_timestamp, id); report counts are
+-- computed per item, so the report tables need an index on the FK.
+
+CREATE INDEX IF NOT EXISTS post_creation_idx ON post (creation_timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS spotted_creation_idx ON spotted (creation_timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS post_status_creation_idx ON post (status, creation_timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS spotted_status_creation_idx ON spotted (status, creation_timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS post_creator_idx ON post (creator);
+CREATE INDEX IF NOT EXISTS spotted_creator_idx ON spotted (creator);
+CREATE INDEX IF NOT EXISTS reported_post_post_idx ON reported_post (post_id);
+CREATE INDEX IF NOT EXISTS reported_spotted_spotted_idx ON reported_spotted (spotted_id);
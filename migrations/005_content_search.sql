@@ -0,0 +1,21 @@
+-- Migration: Full-text search over post and spotted content
+-- Italian stemming with accents removed, so "perché" matches "perche".
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'italian_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION italian_unaccent (COPY = italian);
+        ALTER TEXT SEARCH CONFIGURATION italian_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, italian_stem;
+    END IF;
+END
+$$;
+
+-- The expression must match the one used by the search queries exactly,
+-- otherwise the planner will not use these indexes.
+CREATE INDEX IF NOT EXISTS post_content_fts_idx
+    ON post USING GIN (to_tsvector('italian_unaccent', COALESCE(content, '')));
+CREATE INDEX IF NOT EXISTS spotted_content_fts_idx
+    ON spotted USING GIN (to_tsvector('italian_unaccent', COALESCE(content, '')));
@@ -0,0 +1,30 @@
+-- Migration: Structured rejection reasons
+-- Managed catalogue of rejection reasons; every rejected post/spotted
+-- stores the chosen reason code and an optional free-text note.
+
+CREATE TABLE IF NOT EXISTS rejection_reasons (
+    code TEXT PRIMARY KEY CHECK (code ~ '^[a-z0-9_]+$'),
+    label TEXT NOT NULL,            -- etichetta in italiano mostrata nella dashboard
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,  -- i motivi disattivati restano sugli elementi già rifiutati
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO rejection_reasons (code, label, description) VALUES
+    ('offensive',     'Linguaggio offensivo',     'Insulti, volgarità o contenuti discriminatori'),
+    ('bullying',      'Bullismo o molestie',      'Contenuti che prendono di mira una persona'),
+    ('personal_data', 'Dati personali',           'Numeri di telefono, indirizzi, nomi completi o altri dati identificativi'),
+    ('sexual',        'Contenuto sessuale',       'Contenuti espliciti o allusivi non adatti alla piattaforma'),
+    ('spam',          'Spam o pubblicità',        'Messaggi promozionali, link ripetuti o catene'),
+    ('duplicate',     'Duplicato',                'Contenuto già pubblicato o inviato più volte'),
+    ('off_topic',     'Fuori tema',               'Contenuto non pertinente alla sezione'),
+    ('other',         'Altro',                    'Motivo non previsto dal catalogo: specificare nella nota')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS rejection_reason TEXT REFERENCES rejection_reasons (code) ON UPDATE CASCADE;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS rejection_note TEXT;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS rejection_reason TEXT REFERENCES rejection_reasons (code) ON UPDATE CASCADE;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS rejection_note TEXT;
+
+CREATE INDEX IF NOT EXISTS post_rejection_reason_idx ON post (rejection_reason) WHERE rejection_reason IS NOT NULL;
+CREATE INDEX IF NOT EXISTS spotted_rejection_reason_idx ON spotted (rejection_reason) WHERE rejection_reason IS NOT NULL;
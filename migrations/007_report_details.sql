@@ -0,0 +1,13 @@
+-- Migration: Report details
+-- The report tables only guaranteed (id, <item>_id, user_id). Make sure the
+-- report time and an optional reason are stored so moderators can see who
+-- reported an item, when and why. Existing rows get the migration time.
+
+ALTER TABLE reported_post ADD COLUMN IF NOT EXISTS creation_timestamp TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE reported_post ADD COLUMN IF NOT EXISTS reason TEXT;
+ALTER TABLE reported_spotted ADD COLUMN IF NOT EXISTS creation_timestamp TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE reported_spotted ADD COLUMN IF NOT EXISTS reason TEXT;
+
+-- Mass-reporting detection looks at each user's recent reports
+CREATE INDEX IF NOT EXISTS reported_post_user_idx ON reported_post (user_id, creation_timestamp);
+CREATE INDEX IF NOT EXISTS reported_spotted_user_idx ON reported_spotted (user_id, creation_timestamp);
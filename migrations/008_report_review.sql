@@ -0,0 +1,12 @@
+-- Migration: Dismissable reports
+-- Reports can be marked as reviewed without deleting them: reviewed
+-- reports stay for history but no longer count towards the reported queue.
+
+ALTER TABLE reported_post ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+ALTER TABLE reported_post ADD COLUMN IF NOT EXISTS reviewed_by INTEGER REFERENCES moderators (id) ON DELETE SET NULL;
+ALTER TABLE reported_spotted ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+ALTER TABLE reported_spotted ADD COLUMN IF NOT EXISTS reviewed_by INTEGER REFERENCES moderators (id) ON DELETE SET NULL;
+
+-- Reported queue and report counts only look at open reports
+CREATE INDEX IF NOT EXISTS reported_post_open_idx ON reported_post (post_id) WHERE reviewed_at IS NULL;
+CREATE INDEX IF NOT EXISTS reported_spotted_open_idx ON reported_spotted (spotted_id) WHERE reviewed_at IS NULL;
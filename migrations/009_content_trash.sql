@@ -0,0 +1,18 @@
+-- Migration: Content trash
+-- Deleted posts/spotted are moved here (row, likes and reports as JSONB
+-- snapshots) instead of being dropped, so they can be restored. Items older
+-- than trash_retention_days are purged by the server.
+
+CREATE TABLE IF NOT EXISTS content_trash (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,               -- post, spotted, ...
+    item_id INTEGER NOT NULL,         -- id originale, riusato al ripristino
+    item JSONB NOT NULL,
+    likes JSONB NOT NULL DEFAULT '[]',
+    reports JSONB NOT NULL DEFAULT '[]',
+    deleted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_by INTEGER REFERENCES moderators (id) ON DELETE SET NULL,
+    UNIQUE (kind, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS content_trash_deleted_idx ON content_trash (deleted_at);
@@ -0,0 +1,19 @@
+-- Migration: Banned terms
+-- Managed list of words and regular expressions checked against pending
+-- posts/spotted. Plain terms are matched after normalization (case, accents,
+-- leetspeak, repeated letters, spacing); regex patterns run on the same
+-- normalized text, so they should be written lowercase and without accents.
+
+CREATE TABLE IF NOT EXISTS banned_terms (
+    id SERIAL PRIMARY KEY,
+    pattern TEXT NOT NULL,
+    is_regex BOOLEAN NOT NULL DEFAULT FALSE,
+    severity TEXT NOT NULL DEFAULT 'medium' CHECK (severity IN ('low', 'medium', 'high')),
+    action TEXT NOT NULL DEFAULT 'flag' CHECK (action IN ('flag', 'auto_reject')),
+    -- motivo usato quando action = auto_reject
+    rejection_reason TEXT REFERENCES rejection_reasons (code) ON UPDATE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by INTEGER REFERENCES moderators (id) ON DELETE SET NULL,
+    UNIQUE (pattern, is_regex)
+);
@@ -0,0 +1,12 @@
+-- Migration: Automatic hide on report threshold
+-- Approved items that collect too many reports are moved back to the
+-- pending queue by the server. auto_hidden_at records the last automatic
+-- hide: only reports received after it count towards the next one, so a
+-- moderator re-approving the item is not overruled by the same reports.
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS auto_hidden_at TIMESTAMP;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS auto_hidden_at TIMESTAMP;
+
+-- The threshold check groups open reports by item and time
+CREATE INDEX IF NOT EXISTS reported_post_open_time_idx ON reported_post (post_id, creation_timestamp) WHERE reviewed_at IS NULL;
+CREATE INDEX IF NOT EXISTS reported_spotted_open_time_idx ON reported_spotted (spotted_id, creation_timestamp) WHERE reviewed_at IS NULL;
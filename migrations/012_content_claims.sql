@@ -0,0 +1,20 @@
+-- Migration: Queue claims
+-- A moderator fetching the next pending items claims them for a limited
+-- lease so other moderators skip them. Expired claims are ignored and
+-- overwritten by the next claim. Admins can steal or release any claim.
+
+CREATE TABLE IF NOT EXISTS content_claims (
+    kind TEXT NOT NULL,
+    item_id INTEGER NOT NULL,
+    moderator_id INTEGER NOT NULL REFERENCES moderators (id) ON DELETE CASCADE,
+    claimed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (kind, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS content_claims_moderator_idx ON content_claims (moderator_id, expires_at);
+
+ALTER TABLE moderators ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The default account created by 001_add_moderators.sql is the first admin
+UPDATE moderators SET is_admin = TRUE WHERE username = 'admin';
@@ -0,0 +1,90 @@
+-- Migration: Real-time moderation events
+-- Triggers on content and report tables record an event and NOTIFY the
+-- dashboard server, which streams them to moderators over SSE. Events are
+-- stored (not only notified) so a reconnecting client can resume from the
+-- last event it saw. The server deletes events older than a day.
+--
+-- The acting moderator is read from the transaction setting
+-- app.moderator_id, set by the server before every audited change.
+
+CREATE TABLE IF NOT EXISTS moderation_events (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL CHECK (type IN ('pending_new', 'report_new', 'status_changed')),
+    kind TEXT NOT NULL,
+    item_id INTEGER NOT NULL,
+    status TEXT,
+    school_id INTEGER,
+    city_id INTEGER,
+    actor_id INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS moderation_events_created_idx ON moderation_events (created_at);
+
+CREATE OR REPLACE FUNCTION record_moderation_event(p_type TEXT, p_kind TEXT, p_item INTEGER, p_status INTEGER, p_creator INTEGER)
+RETURNS VOID AS $$
+DECLARE
+    ev_id BIGINT;
+    ev_status TEXT;
+    ev_school INTEGER;
+    ev_city INTEGER;
+BEGIN
+    SELECT description INTO ev_status FROM submit_status WHERE id = p_status;
+    SELECT u.school, s.city INTO ev_school, ev_city
+      FROM users u LEFT JOIN schools s ON u.school = s.id
+     WHERE u.id = p_creator;
+
+    INSERT INTO moderation_events (type, kind, item_id, status, school_id, city_id, actor_id)
+    VALUES (p_type, p_kind, p_item, ev_status, ev_school, ev_city,
+            NULLIF(current_setting('app.moderator_id', true), '')::INTEGER)
+    RETURNING id INTO ev_id;
+
+    PERFORM pg_notify('moderation_events', ev_id::TEXT);
+END;
+$$ LANGUAGE plpgsql;
+
+-- TG_ARGV[0]: content kind name
+CREATE OR REPLACE FUNCTION content_moderation_event() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.status = (SELECT id FROM submit_status WHERE description = 'received') THEN
+            PERFORM record_moderation_event('pending_new', TG_ARGV[0], NEW.id, NEW.status, NEW.creator);
+        END IF;
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        PERFORM record_moderation_event('status_changed', TG_ARGV[0], NEW.id, NEW.status, NEW.creator);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- TG_ARGV[0]: content kind name, [1]: content table, [2]: report FK column
+CREATE OR REPLACE FUNCTION report_moderation_event() RETURNS TRIGGER AS $$
+DECLARE
+    item INTEGER;
+    item_status INTEGER;
+    item_creator INTEGER;
+BEGIN
+    item := (to_jsonb(NEW) ->> TG_ARGV[2])::INTEGER;
+    EXECUTE format('SELECT status, creator FROM %I WHERE id = $1', TG_ARGV[1])
+       INTO item_status, item_creator
+      USING item;
+    PERFORM record_moderation_event('report_new', TG_ARGV[0], item, item_status, item_creator);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS post_moderation_event ON post;
+CREATE TRIGGER post_moderation_event AFTER INSERT OR UPDATE OF status ON post
+    FOR EACH ROW EXECUTE FUNCTION content_moderation_event('post');
+
+DROP TRIGGER IF EXISTS spotted_moderation_event ON spotted;
+CREATE TRIGGER spotted_moderation_event AFTER INSERT OR UPDATE OF status ON spotted
+    FOR EACH ROW EXECUTE FUNCTION content_moderation_event('spotted');
+
+DROP TRIGGER IF EXISTS reported_post_moderation_event ON reported_post;
+CREATE TRIGGER reported_post_moderation_event AFTER INSERT ON reported_post
+    FOR EACH ROW EXECUTE FUNCTION report_moderation_event('post', 'post', 'post_id');
+
+DROP TRIGGER IF EXISTS reported_spotted_moderation_event ON reported_spotted;
+CREATE TRIGGER reported_spotted_moderation_event AFTER INSERT ON reported_spotted
+    FOR EACH ROW EXECUTE FUNCTION report_moderation_event('spotted', 'spotted', 'spotted_id');
@@ -0,0 +1,19 @@
+-- Migration: Content revisions
+-- Moderators can edit the text of a post/spotted (e.g. to remove a surname
+-- or a phone number) instead of rejecting it. Every version is kept here:
+-- on the first edit the author's original text is stored as revision 1,
+-- then each edit or revert adds a new revision with the resulting text.
+-- Revisions survive the trash and are purged together with the item.
+
+CREATE TABLE IF NOT EXISTS content_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,               -- post, spotted, ...
+    item_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    editor_id INTEGER REFERENCES moderators (id) ON DELETE SET NULL,  -- NULL per il testo originale
+    reason TEXT,
+    reverted_from INTEGER,            -- revisione ripristinata, se è un ripristino
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, item_id, revision)
+);
@@ -0,0 +1,16 @@
+-- Migration: Personal data flags
+-- The server scans pending and reported posts/spotted for personal data
+-- (phone numbers, emails, social handles, codice fiscale, addresses).
+-- pii_checked_at marks items already scanned; items containing one of the
+-- types listed in pii_auto_flag get pii_flagged_at and the types found.
+-- Editing the text clears all three so the item is scanned again.
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS pii_checked_at TIMESTAMP;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS pii_flagged_at TIMESTAMP;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS pii_flag_types TEXT[];
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS pii_checked_at TIMESTAMP;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS pii_flagged_at TIMESTAMP;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS pii_flag_types TEXT[];
+
+CREATE INDEX IF NOT EXISTS post_pii_unchecked_idx ON post (id) WHERE pii_checked_at IS NULL;
+CREATE INDEX IF NOT EXISTS spotted_pii_unchecked_idx ON spotted (id) WHERE pii_checked_at IS NULL;
@@ -0,0 +1,27 @@
+-- Migration: Mentions of real students
+-- The server looks for first+last name pairs of students of the author's
+-- school in pending posts/spotted. Every mention found is stored with its
+-- position in the text; mentioned_students counts the distinct students
+-- and raises the item's priority in the pending queue.
+-- mentions_checked_at marks items already scanned; editing the text clears
+-- it so the item is scanned again.
+
+CREATE TABLE IF NOT EXISTS content_mentions (
+    kind TEXT NOT NULL,               -- post, spotted, ...
+    item_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    start_pos INTEGER NOT NULL,       -- posizioni in caratteri Unicode
+    end_pos INTEGER NOT NULL,
+    matched_text TEXT NOT NULL,
+    PRIMARY KEY (kind, item_id, user_id, start_pos)
+);
+
+CREATE INDEX IF NOT EXISTS content_mentions_user_idx ON content_mentions (user_id);
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS mentions_checked_at TIMESTAMP;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS mentioned_students INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS mentions_checked_at TIMESTAMP;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS mentioned_students INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS post_mentions_unchecked_idx ON post (id) WHERE mentions_checked_at IS NULL;
+CREATE INDEX IF NOT EXISTS spotted_mentions_unchecked_idx ON spotted (id) WHERE mentions_checked_at IS NULL;
@@ -0,0 +1,24 @@
+-- Migration: Near-duplicate and burst detection
+-- The server computes a 64-bit simhash of every post/spotted (single-word
+-- features of the normalized text). Pending items within a small Hamming
+-- distance of each other, directly or through a chain of near duplicates,
+-- share dup_group_id (the id of the first item of the group) so they can
+-- be reviewed and actioned together.
+-- burst_flagged_at marks pending items belonging to a burst: one creator
+-- submitting many items in a short window (see `burst` in conf.yaml).
+-- Editing the text clears simhash and dup_group_id so the item is
+-- fingerprinted again.
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS simhash BIGINT;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS dup_group_id INTEGER;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS burst_flagged_at TIMESTAMP;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS simhash BIGINT;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS dup_group_id INTEGER;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS burst_flagged_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS post_simhash_missing_idx ON post (id) WHERE simhash IS NULL;
+CREATE INDEX IF NOT EXISTS spotted_simhash_missing_idx ON spotted (id) WHERE simhash IS NULL;
+CREATE INDEX IF NOT EXISTS post_dup_group_idx ON post (dup_group_id) WHERE dup_group_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS spotted_dup_group_idx ON spotted (dup_group_id) WHERE dup_group_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS post_creator_time_idx ON post (creator, creation_timestamp);
+CREATE INDEX IF NOT EXISTS spotted_creator_time_idx ON spotted (creator, creation_timestamp);
@@ -0,0 +1,39 @@
+-- Migration: Re-submissions of rejected content
+-- Every rejection stores the hash of the normalized text (exact matches)
+-- and its simhash (near-exact matches) together with the decision, so the
+-- record survives even if the rejected item is later deleted. Reversing a
+-- rejection removes the record.
+-- New pending items matching a record point to it with resubmission_of;
+-- resubmission_distance is 0 for exact matches, otherwise the number of
+-- differing simhash bits. Editing the text clears the match together with
+-- the fingerprint so the item is checked again.
+
+CREATE TABLE IF NOT EXISTS rejected_fingerprints (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,                -- post, spotted, ...
+    item_id INTEGER NOT NULL,
+    content_hash BIGINT NOT NULL,
+    simhash BIGINT NOT NULL,
+    rejection_reason TEXT REFERENCES rejection_reasons (code) ON UPDATE CASCADE ON DELETE SET NULL,
+    rejection_note TEXT,
+    moderator_id INTEGER REFERENCES moderators (id) ON DELETE SET NULL,
+    actor_role TEXT,                   -- full, cli, system; NULL if unknown
+    rejected_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS rejected_fingerprints_hash_idx ON rejected_fingerprints (kind, content_hash);
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS resubmission_of INTEGER REFERENCES rejected_fingerprints (id) ON DELETE SET NULL;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS resubmission_distance INTEGER;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS resubmission_of INTEGER REFERENCES rejected_fingerprints (id) ON DELETE SET NULL;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS resubmission_distance INTEGER;
+
+CREATE INDEX IF NOT EXISTS post_resubmission_idx ON post (resubmission_of) WHERE resubmission_of IS NOT NULL;
+CREATE INDEX IF NOT EXISTS spotted_resubmission_idx ON spotted (resubmission_of) WHERE resubmission_of IS NOT NULL;
+
+-- Pending items fingerprinted before this migration are checked again
+UPDATE post SET simhash = NULL
+ WHERE status = (SELECT id FROM submit_status WHERE description = 'received');
+UPDATE spotted SET simhash = NULL
+ WHERE status = (SELECT id FROM submit_status WHERE description = 'received');
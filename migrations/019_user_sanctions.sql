@@ -0,0 +1,59 @@
+-- Migration: User sanctions
+-- Sanctions against repeat offenders, kept as history with reason and
+-- moderator:
+--   warning      no effect, recorded for the record
+--   suspension   temporary, expires_at required
+--   ban          permanent until lifted
+--   shadow_ban   optional expiry; new content is held (held_at) and left
+--                out of the pending queue, the author is not told
+-- The server lifts expired sanctions. The columns on users summarise the
+-- active sanctions and are recomputed by the server on every change, so
+-- the main app only has to read them.
+-- Expiry times come from the API as absolute instants (client time zone or
+-- server clock), so all sanction times (including the summary columns on
+-- users and held_at) are TIMESTAMPTZ and compared with NOW() whatever the
+-- session time zone.
+
+CREATE TABLE IF NOT EXISTS user_sanctions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('warning', 'suspension', 'ban', 'shadow_ban')),
+    reason TEXT NOT NULL,
+    moderator_id INTEGER REFERENCES moderators (id) ON DELETE SET NULL,
+    actor_role TEXT NOT NULL,           -- full, users_only
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    lifted_at TIMESTAMPTZ,
+    lifted_by INTEGER REFERENCES moderators (id) ON DELETE SET NULL,
+    lift_reason TEXT,                   -- 'expired' when lifted by the server
+    CHECK (type <> 'suspension' OR expires_at IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS user_sanctions_user_idx ON user_sanctions (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS user_sanctions_expiring_idx ON user_sanctions (expires_at)
+    WHERE lifted_at IS NULL AND expires_at IS NOT NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS shadow_banned_at TIMESTAMPTZ;
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS held_at TIMESTAMPTZ;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS held_at TIMESTAMPTZ;
+
+-- New content from shadow-banned users is held as soon as it is inserted
+CREATE OR REPLACE FUNCTION hold_shadow_banned_content() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE id = NEW.creator AND shadow_banned_at IS NOT NULL) THEN
+        NEW.held_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS post_hold_shadow_banned ON post;
+CREATE TRIGGER post_hold_shadow_banned BEFORE INSERT ON post
+    FOR EACH ROW EXECUTE FUNCTION hold_shadow_banned_content();
+
+DROP TRIGGER IF EXISTS spotted_hold_shadow_banned ON spotted;
+CREATE TRIGGER spotted_hold_shadow_banned BEFORE INSERT ON spotted
+    FOR EACH ROW EXECUTE FUNCTION hold_shadow_banned_content();
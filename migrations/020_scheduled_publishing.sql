@@ -0,0 +1,15 @@
+-- Migration: Scheduled publishing
+-- Posts and spotted approved with a publish time stay in status 'received'
+-- with publish_at set: they are hidden from the app and left out of the
+-- pending queue. The server approves them (setting approval_timestamp)
+-- once publish_at has passed. publish_at is a TIMESTAMPTZ: the dashboard
+-- sends absolute instants and the comparison with NOW() must not depend on
+-- the session time zone.
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE post ADD COLUMN IF NOT EXISTS scheduled_by INTEGER REFERENCES moderators (id) ON DELETE SET NULL;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS scheduled_by INTEGER REFERENCES moderators (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS post_publish_at_idx ON post (publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS spotted_publish_at_idx ON spotted (publish_at) WHERE publish_at IS NOT NULL;
@@ -0,0 +1,29 @@
+-- Migration: Banned term scan marker
+-- terms_checked_at marks pending posts/spotted already scanned for banned
+-- terms with action auto_reject, so the scanner only reads new items.
+-- Editing the text clears it, and so does any change to banned_terms for
+-- pending items, which are scanned again with the new list. Items that
+-- return to the pending queue (status change, auto-hide, restore from the
+-- trash) have it cleared by the server.
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS terms_checked_at TIMESTAMP;
+ALTER TABLE spotted ADD COLUMN IF NOT EXISTS terms_checked_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS post_terms_unchecked_idx ON post (id) WHERE terms_checked_at IS NULL;
+CREATE INDEX IF NOT EXISTS spotted_terms_unchecked_idx ON spotted (id) WHERE terms_checked_at IS NULL;
+
+CREATE OR REPLACE FUNCTION reset_terms_checked() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE post SET terms_checked_at = NULL
+     WHERE terms_checked_at IS NOT NULL
+       AND status = (SELECT id FROM submit_status WHERE description = 'received');
+    UPDATE spotted SET terms_checked_at = NULL
+     WHERE terms_checked_at IS NOT NULL
+       AND status = (SELECT id FROM submit_status WHERE description = 'received');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS banned_terms_reset_checked ON banned_terms;
+CREATE TRIGGER banned_terms_reset_checked AFTER INSERT OR UPDATE OR DELETE ON banned_terms
+    FOR EACH STATEMENT EXECUTE FUNCTION reset_terms_checked();
@@ -8,6 +8,13 @@ type Moderator struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Name     string `json:"name"`
+	IsAdmin  bool   `json:"is_admin"`
+}
+
+type ModeratorAccount struct {
+	Moderator
+	Disabled  bool       `json:"disabled"`
+	CreatedAt *time.Time `json:"created_at"`
 }
 
 type LoginRequest struct {
@@ -58,82 +65,429 @@ type SchoolUpdateRequest struct {
 	EmailDomain string `json:"email_domain"`
 }
 
-// ==================== POST ====================
+// ==================== CONTENT (POST, SPOTTED) ====================
 
-type PendingPost struct {
+// ContentItem è un elemento moderabile di qualsiasi tipo. I campi di
+// visibilità esistono solo per gli spotted e sono omessi per gli altri tipi.
+type ContentItem struct {
 	ID                int       `json:"id"`
+	Kind              string    `json:"kind"`
 	Content           string    `json:"content"`
 	CreatorID         int       `json:"creator_id"`
 	CreationTimestamp time.Time `json:"creation_timestamp"`
 	LikesCount        int       `json:"likes_count"`
+	Visibility        *int      `json:"visibility,omitempty"`
+	VisibilityDesc    *string   `json:"visibility_desc,omitempty"`
+	Color             *string   `json:"color,omitempty"`
 	CreatorFirstName  string    `json:"creator_first_name"`
 	CreatorLastName   string    `json:"creator_last_name"`
 	CreatorEmail      string    `json:"creator_email"`
 	SchoolName        *string   `json:"school_name"`
 	CityName          *string   `json:"city_name"`
+	Status            string    `json:"status"`
+	ReportCount       int       `json:"report_count"`
+
+	// Valorizzati solo per gli elementi rifiutati
+	RejectionReason      *string `json:"rejection_reason"`
+	RejectionReasonLabel *string `json:"rejection_reason_label"`
+	RejectionNote        *string `json:"rejection_note"`
+
+	// Ultimo occultamento automatico per troppe segnalazioni; se lo stato è
+	// ancora received l'elemento attende la revisione di un moderatore
+	AutoHiddenAt *time.Time `json:"auto_hidden_at"`
+
+	// Segnalazione automatica per dati personali e tipi trovati
+	PIIFlaggedAt *time.Time `json:"pii_flagged_at"`
+	PIIFlagTypes []string   `json:"pii_flag_types"`
+
+	// Moderatore che ha riservato l'elemento, se la riserva non è scaduta
+	ClaimedBy      *int       `json:"claimed_by"`
+	ClaimedByName  *string    `json:"claimed_by_name"`
+	ClaimExpiresAt *time.Time `json:"claim_expires_at"`
+
+	// Ultima modifica del testo da parte di un moderatore, se modificato
+	EditedAt *time.Time `json:"edited_at"`
+
+	// Termini vietati trovati nel testo (solo nella coda in attesa)
+	TermMatches []TermMatch `json:"term_matches,omitempty"`
+
+	// Dati personali trovati nel testo (code in attesa e segnalati)
+	PIIMatches []PIIMatch `json:"pii_matches,omitempty"`
+
+	// Studenti della scuola dell'autore nominati nel testo: il numero alza
+	// la priorità nella coda, le menzioni sono allegate nella coda in attesa
+	MentionedStudents int              `json:"mentioned_students"`
+	Mentions          []StudentMention `json:"mentions,omitempty"`
+
+	// Gruppo di quasi duplicati (ID del primo elemento) e quanti elementi
+	// dello stesso stato ne fanno parte
+	DupGroupID   *int `json:"dup_group_id"`
+	DupGroupSize int  `json:"dup_group_size"`
+
+	// Segnalazione automatica per troppi invii ravvicinati dello stesso autore
+	BurstFlaggedAt *time.Time `json:"burst_flagged_at"`
+
+	// Reinvio di un contenuto già rifiutato: 0 se il testo normalizzato è
+	// identico, altrimenti i bit di differenza tra le impronte. La decisione
+	// originale è allegata nella coda in attesa.
+	ResubmissionDistance *int          `json:"resubmission_distance"`
+	Resubmission         *Resubmission `json:"resubmission,omitempty"`
+
+	// Trattenuto perché l'autore è in shadow-ban: fuori dalla coda in attesa
+	HeldAt *time.Time `json:"held_at"`
+
+	// Pubblicazione programmata: l'elemento resta received e fuori dalla
+	// coda in attesa finché il server non lo approva a publish_at
+	PublishAt       *time.Time `json:"publish_at"`
+	ScheduledBy     *int       `json:"scheduled_by"`
+	ScheduledByName *string    `json:"scheduled_by_name"`
 }
 
-type ReportedPost struct {
-	PendingPost
-	ReportCount int `json:"report_count"`
+// Resubmission descrive il rifiuto del contenuto che un elemento ripropone
+type Resubmission struct {
+	RejectedItemID int       `json:"rejected_item_id"`
+	Exact          bool      `json:"exact"`
+	Distance       int       `json:"distance"`
+	RejectedAt     time.Time `json:"rejected_at"`
+	Reason         *string   `json:"reason"`
+	ReasonLabel    *string   `json:"reason_label"`
+	Note           *string   `json:"note"`
+	ModeratorID    *int      `json:"moderator_id"`
+	ModeratorName  *string   `json:"moderator_name"`
+	ActorRole      *string   `json:"actor_role"`
 }
 
-type AllPost struct {
-	ID                int       `json:"id"`
-	Content           string    `json:"content"`
-	CreatorID         int       `json:"creator_id"`
-	CreationTimestamp time.Time `json:"creation_timestamp"`
-	LikesCount        int       `json:"likes_count"`
-	CreatorFirstName  string    `json:"creator_first_name"`
-	CreatorLastName   string    `json:"creator_last_name"`
-	CreatorEmail      string    `json:"creator_email"`
-	SchoolName        *string   `json:"school_name"`
-	CityName          *string   `json:"city_name"`
-	Status            string    `json:"status"`
+// SimilarItem è un elemento simile con la distanza tra le impronte (bit diversi)
+type SimilarItem struct {
+	ContentItem
+	Distance int `json:"distance"`
+}
+
+// ContentBurst riassume gli elementi in attesa di un autore segnati come raffica
+type ContentBurst struct {
+	CreatorID        int       `json:"creator_id"`
+	CreatorFirstName string    `json:"creator_first_name"`
+	CreatorLastName  string    `json:"creator_last_name"`
+	CreatorEmail     string    `json:"creator_email"`
+	Items            int       `json:"items"`
+	FirstAt          time.Time `json:"first_at"`
+	LastAt           time.Time `json:"last_at"`
+	ItemIDs          []int     `json:"item_ids"`
+}
+
+// SearchResult è un risultato della ricerca full-text con rilevanza e
+// snippet in cui i termini trovati sono racchiusi in <mark>.
+type SearchResult struct {
+	ContentItem
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
 }
 
 type SetStatusRequest struct {
 	Status string `json:"status"`
+	Reason string `json:"reason"` // solo per status=rejected
+	Note   string `json:"note"`
 }
 
-// ==================== SPOTTED ====================
+// RejectRequest è il corpo (facoltativo) di PUT /:id/reject
+type RejectRequest struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note"`
+}
 
-type PendingSpotted struct {
-	ID                int       `json:"id"`
-	Content           string    `json:"content"`
-	CreatorID         int       `json:"creator_id"`
-	CreationTimestamp time.Time `json:"creation_timestamp"`
-	LikesCount        int       `json:"likes_count"`
-	Visibility        int       `json:"visibility"`
-	VisibilityDesc    string    `json:"visibility_desc"`
-	Color             string    `json:"color"`
-	CreatorFirstName  string    `json:"creator_first_name"`
-	CreatorLastName   string    `json:"creator_last_name"`
-	CreatorEmail      string    `json:"creator_email"`
-	SchoolName        *string   `json:"school_name"`
-	CityName          *string   `json:"city_name"`
+// ScheduleRequest è il corpo (facoltativo per approve) di PUT /:id/approve e
+// PUT /:id/schedule: con publish_at l'elemento viene pubblicato a quell'ora
+type ScheduleRequest struct {
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// ScheduleResponse conferma la programmazione; PublishAt è nil se annullata
+type ScheduleResponse struct {
+	Status    string     `json:"status"`
+	Msg       string     `json:"msg"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// Lunghezza massima della nota libera di rifiuto
+const MAX_REJECTION_NOTE = 1000
+
+// BulkFilter seleziona gli elementi di un'azione massiva con gli stessi
+// criteri delle liste; queue vale pending, reported o all (default).
+type BulkFilter struct {
+	Queue      string     `json:"queue"`
+	Status     string     `json:"status"`
+	CityID     *int       `json:"city_id"`
+	SchoolID   *int       `json:"school_id"`
+	CreatorID  *int       `json:"creator_id"`
+	From       *time.Time `json:"from"`
+	To         *time.Time `json:"to"`
+	MinReports *int       `json:"min_reports"`
+	// Quasi duplicati di un gruppo, per trattarli insieme
+	DupGroup      *int `json:"dup_group"`
+	Burst         bool `json:"burst"`
+	Resubmissions bool `json:"resubmissions"`
+	Held          bool `json:"held"`
+}
+
+// BulkRequest applica action agli elementi indicati da ids oppure da filter.
+// Con atomic=true o vanno a buon fine tutti o nessuno.
+type BulkRequest struct {
+	Action string      `json:"action"` // approve, reject, set_status, delete, dismiss_reports
+	Status string      `json:"status"` // solo per set_status
+	IDs    []int       `json:"ids"`
+	Filter *BulkFilter `json:"filter"`
+	Atomic bool        `json:"atomic"`
+	Reason string      `json:"reason"` // per reject e set_status=rejected
+	Note   string      `json:"note"`
 }
 
-type ReportedSpotted struct {
-	PendingSpotted
-	ReportCount int `json:"report_count"`
+// BulkItemResult è l'esito di un singolo elemento: ok, error, oppure
+// rolled_back/skipped quando una richiesta atomica viene annullata.
+type BulkItemResult struct {
+	ID     int    `json:"id"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+	Msg    string `json:"msg,omitempty"`
 }
 
-type AllSpotted struct {
+type BulkResponse struct {
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	Msg       string           `json:"msg"`
+	Action    string           `json:"action"`
+	Atomic    bool             `json:"atomic"`
+	Requested int              `json:"requested"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// Stati ammessi per post e spotted
+var validContentStatuses = map[string]bool{"received": true, "approved": true, "rejected": true}
+
+// ContentReport è una singola segnalazione con i dati di chi l'ha inviata
+type ContentReport struct {
 	ID                int       `json:"id"`
-	Content           string    `json:"content"`
-	CreatorID         int       `json:"creator_id"`
-	CreationTimestamp time.Time `json:"creation_timestamp"`
-	LikesCount        int       `json:"likes_count"`
-	Visibility        int       `json:"visibility"`
-	VisibilityDesc    string    `json:"visibility_desc"`
-	Color             string    `json:"color"`
-	CreatorFirstName  string    `json:"creator_first_name"`
-	CreatorLastName   string    `json:"creator_last_name"`
-	CreatorEmail      string    `json:"creator_email"`
-	SchoolName        *string   `json:"school_name"`
-	CityName          *string   `json:"city_name"`
-	Status            string    `json:"status"`
+	ReporterID        int       `json:"reporter_id"`
+	ReporterFirstName string    `json:"reporter_first_name"`
+	ReporterLastName  string    `json:"reporter_last_name"`
+	ReporterEmail     string    `json:"reporter_email"`
+	ReporterSchool    *string   `json:"reporter_school"`
+	CreatedAt         time.Time `json:"created_at"`
+	Reason            *string   `json:"reason"`
+
+	// Valorizzati quando la segnalazione è stata archiviata da un moderatore
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	ReviewedByID   *int       `json:"reviewed_by_id"`
+	ReviewedByName *string    `json:"reviewed_by_name"`
+}
+
+// ReportReasonCount raggruppa le segnalazioni per motivo (null se non indicato)
+type ReportReasonCount struct {
+	Reason *string `json:"reason"`
+	Count  int     `json:"count"`
+}
+
+// ReporterSummary riassume l'attività di un segnalatore: quante volte ha
+// segnalato questo elemento e quante segnalazioni ha inviato di recente su
+// tutti i contenuti. MassReporting è vero se supera una delle soglie.
+type ReporterSummary struct {
+	ReporterID    int    `json:"reporter_id"`
+	Name          string `json:"name"`
+	ReportsOnItem int    `json:"reports_on_item"`
+	RecentReports int    `json:"recent_reports"`
+	MassReporting bool   `json:"mass_reporting"`
+}
+
+type ContentReports struct {
+	Kind              string              `json:"kind"`
+	ItemID            int                 `json:"item_id"`
+	Total             int                 `json:"total"`
+	Open              int                 `json:"open"` // non ancora esaminate
+	DistinctReporters int                 `json:"distinct_reporters"`
+	Reports           []ContentReport     `json:"reports"`
+	ByReason          []ReportReasonCount `json:"by_reason"`
+	Reporters         []ReporterSummary   `json:"reporters"`
+	MassReporters     int                 `json:"mass_reporters"`
+}
+
+// ==================== REJECTION REASONS ====================
+
+type RejectionReason struct {
+	Code        string `json:"code"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Active      bool   `json:"active"`
+}
+
+type RejectionReasonRequest struct {
+	Code        string `json:"code"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Active      *bool  `json:"active"`
+}
+
+// ==================== CLAIMS ====================
+
+// ContentClaim è la riserva di un elemento da parte di un moderatore
+type ContentClaim struct {
+	Kind          string    `json:"kind"`
+	ItemID        int       `json:"item_id"`
+	ModeratorID   int       `json:"moderator_id"`
+	ModeratorName string    `json:"moderator_name"`
+	ClaimedAt     time.Time `json:"claimed_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type ClaimResponse struct {
+	Status string       `json:"status"`
+	Msg    string       `json:"msg"`
+	Claim  ContentClaim `json:"claim"`
+}
+
+// ClaimNextResponse restituisce gli elementi appena riservati
+type ClaimNextResponse struct {
+	Status         string        `json:"status"`
+	Data           []ContentItem `json:"data"`
+	LeaseExpiresAt time.Time     `json:"lease_expires_at"`
+}
+
+// ==================== REVISIONS ====================
+
+// ContentRevision è una versione del testo di un elemento. La revisione 1 è
+// il testo originale dell'autore (senza editor); le successive sono modifiche
+// o ripristini dei moderatori. Diff è il confronto con la revisione precedente.
+type ContentRevision struct {
+	ID           int64     `json:"id"`
+	Kind         string    `json:"kind"`
+	ItemID       int       `json:"item_id"`
+	Revision     int       `json:"revision"`
+	Content      string    `json:"content"`
+	EditorID     *int      `json:"editor_id"`
+	EditorName   *string   `json:"editor_name"`
+	Reason       *string   `json:"reason"`
+	RevertedFrom *int      `json:"reverted_from"`
+	CreatedAt    time.Time `json:"created_at"`
+	Diff         []DiffOp  `json:"diff,omitempty"`
+}
+
+// DiffOp è un tratto di testo uguale, aggiunto o tolto tra due revisioni
+type DiffOp struct {
+	Op   string `json:"op"` // equal, insert, delete
+	Text string `json:"text"`
+}
+
+type EditContentRequest struct {
+	Content string  `json:"content"`
+	Reason  *string `json:"reason"`
+}
+
+type RevertContentRequest struct {
+	Reason *string `json:"reason"`
+}
+
+type RevisionResponse struct {
+	Status   string `json:"status"`
+	Msg      string `json:"msg"`
+	Revision int    `json:"revision"`
+}
+
+type RevisionDiffResponse struct {
+	Status string   `json:"status"`
+	From   int      `json:"from"`
+	To     int      `json:"to"`
+	Diff   []DiffOp `json:"diff"`
+}
+
+// ==================== EVENTS ====================
+
+// ModerationEvent è un evento in tempo reale: nuovo elemento in attesa
+// (pending_new), nuova segnalazione (report_new) o cambio di stato
+// (status_changed). ActorID è il moderatore che ha causato il cambio, se noto.
+type ModerationEvent struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Kind      string    `json:"kind"`
+	ItemID    int       `json:"item_id"`
+	Status    *string   `json:"status"`
+	SchoolID  *int      `json:"school_id"`
+	CityID    *int      `json:"city_id"`
+	ActorID   *int      `json:"actor_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ==================== BANNED TERMS ====================
+
+// BannedTerm è una parola o espressione regolare vietata. Severity: low,
+// medium, high; Action: flag (solo evidenziato) o auto_reject.
+type BannedTerm struct {
+	ID              int       `json:"id"`
+	Pattern         string    `json:"pattern"`
+	IsRegex         bool      `json:"is_regex"`
+	Severity        string    `json:"severity"`
+	Action          string    `json:"action"`
+	RejectionReason *string   `json:"rejection_reason"`
+	Active          bool      `json:"active"`
+	CreatedAt       time.Time `json:"created_at"`
+	CreatedByName   *string   `json:"created_by_name"`
+}
+
+type BannedTermRequest struct {
+	Pattern         string  `json:"pattern"`
+	IsRegex         bool    `json:"is_regex"`
+	Severity        string  `json:"severity"`
+	Action          string  `json:"action"`
+	RejectionReason *string `json:"rejection_reason"`
+	Active          *bool   `json:"active"`
+}
+
+// TermMatch è un'occorrenza di un termine vietato; Start ed End sono
+// posizioni in caratteri Unicode nel testo originale (End escluso).
+type TermMatch struct {
+	TermID          int     `json:"term_id"`
+	Pattern         string  `json:"pattern"`
+	Severity        string  `json:"severity"`
+	Action          string  `json:"action"`
+	RejectionReason *string `json:"-"`
+	Start           int     `json:"start"`
+	End             int     `json:"end"`
+	Text            string  `json:"text"`
+}
+
+type TermTestRequest struct {
+	Text string `json:"text"`
+}
+
+// ==================== PERSONAL DATA ====================
+
+// PIIMatch è un dato personale trovato in un testo; Start ed End sono
+// posizioni in caratteri Unicode (End esclusa)
+type PIIMatch struct {
+	Type  string `json:"type"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// ==================== MENTIONS ====================
+
+// SchoolStudent è un utente con cui confrontare i nomi citati nei testi
+type SchoolStudent struct {
+	ID        int
+	FirstName string
+	LastName  string
+}
+
+// StudentMention è uno studente reale nominato in un testo; Start ed End
+// sono posizioni in caratteri Unicode (End esclusa)
+type StudentMention struct {
+	UserID    int    `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email,omitempty"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	Text      string `json:"text"`
 }
 
 // ==================== USERS ====================
@@ -147,12 +501,118 @@ type User struct {
 	Role          string  `json:"role"`
 	SchoolName    *string `json:"school_name"`
 	CityName      *string `json:"city_name"`
+
+	// Sanzioni attive (vedi /api/users/:id/sanctions)
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	BannedAt       *time.Time `json:"banned_at"`
+	ShadowBannedAt *time.Time `json:"shadow_banned_at"`
 }
 
 type SetRoleRequest struct {
 	Role string `json:"role"`
 }
 
+// Ruoli assegnabili - only allow user and representative for now
+var validUserRoles = map[string]bool{"user": true, "representative": true}
+
+// UserContentStats riassume i contenuti di un utente di un tipo (o di tutti)
+type UserContentStats struct {
+	Total           int `json:"total"`
+	Pending         int `json:"pending"`
+	Approved        int `json:"approved"`
+	Rejected        int `json:"rejected"`
+	Held            int `json:"held"`
+	Deleted         int `json:"deleted"` // nel cestino
+	LikesReceived   int `json:"likes_received"`
+	ReportsReceived int `json:"reports_received"`
+	OpenReports     int `json:"open_reports"`
+	ReportsFiled    int `json:"reports_filed"`
+}
+
+// ProfileItem è un elemento recente dell'utente; Link porta al dettaglio
+type ProfileItem struct {
+	ContentItem
+	Link string `json:"link"`
+}
+
+// RoleChange è un cambio di ruolo preso dal log di audit
+type RoleChange struct {
+	ChangedAt     time.Time `json:"changed_at"`
+	From          *string   `json:"from"`
+	To            *string   `json:"to"`
+	ModeratorID   *int      `json:"moderator_id"`
+	ModeratorName *string   `json:"moderator_name"`
+	ActorRole     string    `json:"actor_role"`
+}
+
+// UserProfile raccoglie ciò che serve per valutare un autore.
+// RejectionRate è rifiutati / (approvati + rifiutati), nil senza decisioni.
+type UserProfile struct {
+	User
+	AccountCreatedAt *time.Time                  `json:"account_created_at"`
+	AccountAgeDays   *int                        `json:"account_age_days"`
+	Totals           UserContentStats            `json:"totals"`
+	ByKind           map[string]UserContentStats `json:"by_kind"`
+	RejectionRate    *float64                    `json:"rejection_rate"`
+	LatestItems      []ProfileItem               `json:"latest_items"`
+	RoleHistory      []RoleChange                `json:"role_history"`
+	ActiveSanctions  []UserSanction              `json:"active_sanctions"`
+}
+
+// UserSanction è una sanzione, attiva o passata. Active è vero finché non
+// viene revocata o non scade (un avvertimento non è mai attivo).
+type UserSanction struct {
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	Type          string     `json:"type"` // warning, suspension, ban, shadow_ban
+	Reason        string     `json:"reason"`
+	ModeratorID   *int       `json:"moderator_id"`
+	ModeratorName *string    `json:"moderator_name"`
+	ActorRole     string     `json:"actor_role"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	LiftedAt      *time.Time `json:"lifted_at"`
+	LiftedBy      *int       `json:"lifted_by"`
+	LiftedByName  *string    `json:"lifted_by_name"`
+	LiftReason    *string    `json:"lift_reason"`
+	Active        bool       `json:"active"`
+}
+
+// SanctionRequest è il corpo di POST /users/:id/sanctions. La scadenza si
+// indica con duration_hours oppure expires_at (obbligatoria per suspension,
+// facoltativa per shadow_ban). Con reject_pending vengono rifiutati anche gli
+// elementi in attesa dell'utente, con motivo e nota facoltativi.
+type SanctionRequest struct {
+	Type            string     `json:"type"`
+	Reason          string     `json:"reason"`
+	DurationHours   int        `json:"duration_hours"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	RejectPending   bool       `json:"reject_pending"`
+	RejectionReason string     `json:"rejection_reason"`
+	RejectionNote   string     `json:"rejection_note"`
+}
+
+// LiftSanctionRequest è il corpo (facoltativo) della revoca di una sanzione
+type LiftSanctionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// UserSanctions riassume le sanzioni attive di un utente con lo storico
+type UserSanctions struct {
+	SuspendedUntil *time.Time     `json:"suspended_until"`
+	BannedAt       *time.Time     `json:"banned_at"`
+	ShadowBannedAt *time.Time     `json:"shadow_banned_at"`
+	Sanctions      []UserSanction `json:"sanctions"`
+}
+
+// SanctionResult è l'esito di una nuova sanzione, con i rifiuti degli
+// elementi in attesa se richiesti
+type SanctionResult struct {
+	Sanction        UserSanction     `json:"sanction"`
+	RejectedPending int              `json:"rejected_pending"`
+	RejectFailures  []BulkItemResult `json:"reject_failures,omitempty"`
+}
+
 // ==================== STATISTICS ====================
 
 type TotalStats struct {
@@ -207,6 +667,15 @@ type TopSchool struct {
 	UserCount int    `json:"user_count"`
 }
 
+// RejectionReasonStats conta gli elementi rifiutati per motivo;
+// Code è null per i rifiuti senza motivo (precedenti al catalogo).
+type RejectionReasonStats struct {
+	Code         *string `json:"code"`
+	Label        string  `json:"label"`
+	PostCount    int     `json:"post_count"`
+	SpottedCount int     `json:"spotted_count"`
+}
+
 type FullStatistics struct {
 	Totals        TotalStats    `json:"totals"`
 	CitiesStats   []CityStats   `json:"cities_stats"`
@@ -216,6 +685,24 @@ type FullStatistics struct {
 	SpottedOverTime []TimeStats `json:"spotted_over_time"`
 	TopCities     []TopCity     `json:"top_cities"`
 	TopSchools    []TopSchool   `json:"top_schools"`
+	RejectionReasons []RejectionReasonStats `json:"rejection_reasons"`
+}
+
+// ==================== AUDIT ====================
+
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ActorID    *int      `json:"actor_id"`
+	ActorRole  string    `json:"actor_role"`
+	ActorName  *string   `json:"actor_name"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   int       `json:"target_id"`
+	Before     any       `json:"before"`
+	After      any       `json:"after"`
+	IP         *string   `json:"ip"`
+	RequestID  *string   `json:"request_id"`
 }
 
 // ==================== GENERIC RESPONSES ====================
@@ -226,12 +713,87 @@ type SuccessResponse struct {
 }
 
 type ErrorResponse struct {
+	Status     string `json:"status"`
+	Error      string `json:"error"`
+	Msg        string `json:"msg"`
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// TrashItem è un elemento nel cestino; PurgeAt indica quando verrà
+// eliminato definitivamente.
+type TrashItem struct {
+	ID                int        `json:"id"`
+	Kind              string     `json:"kind"`
+	Content           string     `json:"content"`
+	CreatorID         *int       `json:"creator_id"`
+	CreatorFirstName  string     `json:"creator_first_name"`
+	CreatorLastName   string     `json:"creator_last_name"`
+	CreationTimestamp *time.Time `json:"creation_timestamp"`
+	LikesCount        int        `json:"likes_count"`
+	ReportCount       int        `json:"report_count"`
+	DeletedAt         time.Time  `json:"deleted_at"`
+	DeletedByID       *int       `json:"deleted_by_id"`
+	DeletedByName     *string    `json:"deleted_by_name"`
+	PurgeAt           time.Time  `json:"purge_at"`
+}
+
+type RestoreResult struct {
+	LikesRestored   int64 `json:"likes_restored"`
+	ReportsRestored int64 `json:"reports_restored"`
+}
+
+type RestoreResponse struct {
 	Status string `json:"status"`
-	Error  string `json:"error"`
 	Msg    string `json:"msg"`
+	RestoreResult
+}
+
+// PurgedItem è un elemento rimosso definitivamente dal cestino
+type PurgedItem struct {
+	Kind      string
+	ItemID    int
+	Content   string
+	DeletedAt time.Time
+}
+
+// DismissResponse è la risposta all'archiviazione delle segnalazioni
+type DismissResponse struct {
+	Status    string `json:"status"`
+	Msg       string `json:"msg"`
+	Dismissed int64  `json:"dismissed"`
+}
+
+// DeleteResult conta like e segnalazioni spostati nel cestino insieme all'elemento
+type DeleteResult struct {
+	LikesDeleted   int64 `json:"likes_deleted"`
+	ReportsDeleted int64 `json:"reports_deleted"`
+}
+
+type DeleteResponse struct {
+	Status string `json:"status"`
+	Msg    string `json:"msg,omitempty"`
+	DeleteResult
 }
 
 type DataResponse struct {
 	Status string `json:"status"`
 	Data   any    `json:"data"`
 }
+
+type PagedResponse struct {
+	Status   string `json:"status"`
+	Data     any    `json:"data"`
+	Total    int    `json:"total"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
+
+// CursorResponse è la risposta delle liste paginate a cursore:
+// next_cursor è null sull'ultima pagina.
+type CursorResponse struct {
+	Status     string  `json:"status"`
+	Data       any     `json:"data"`
+	Total      int     `json:"total"`
+	PageSize   int     `json:"page_size"`
+	NextCursor *string `json:"next_cursor"`
+}
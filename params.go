@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DEFAULT_PAGE_SIZE = 50
+	MAX_PAGE_SIZE     = 200
+)
+
+// parsePagination legge ?page= e ?page_size= con valori di default e limiti
+func parsePagination(ctx *gin.Context) (int, int, bool) {
+	page, pageSize := 1, DEFAULT_PAGE_SIZE
+	var err error
+
+	if v := ctx.Query("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_page",
+				Msg:    "page must be a positive integer",
+			})
+			return 0, 0, false
+		}
+	}
+	if v := ctx.Query("page_size"); v != "" {
+		if pageSize, err = strconv.Atoi(v); err != nil || pageSize < 1 || pageSize > MAX_PAGE_SIZE {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_page_size",
+				Msg:    "page_size must be between 1 and " + strconv.Itoa(MAX_PAGE_SIZE),
+			})
+			return 0, 0, false
+		}
+	}
+	return page, pageSize, true
+}
+
+// queryInt legge un parametro intero opzionale; nil se assente
+func queryInt(ctx *gin.Context, name string) (*int, bool) {
+	v := ctx.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_" + name,
+			Msg:    name + " must be an integer",
+		})
+		return nil, false
+	}
+	return &n, true
+}
+
+// queryTime legge un parametro data opzionale (RFC3339 o YYYY-MM-DD); nil se assente
+func queryTime(ctx *gin.Context, name string) (*time.Time, bool) {
+	v := ctx.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", v)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_" + name,
+			Msg:    name + " must be a date (YYYY-MM-DD) or RFC3339 timestamp",
+		})
+		return nil, false
+	}
+	return &t, true
+}
+
+func encodeCursor(cursor ContentCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*ContentCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor ContentCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// parseContentListParams legge paginazione, filtri e ordinamento di una lista
+// di contenuti. defaultSort si applica quando ?sort= è assente.
+func parseContentListParams(ctx *gin.Context, queue, defaultSort string) (ContentListParams, bool) {
+	params := ContentListParams{
+		Queue:  queue,
+		Sort:   defaultSort,
+		Limit:  DEFAULT_PAGE_SIZE,
+		Status: ctx.Query("status"),
+		// ?auto_hidden=true limita agli elementi nascosti per troppe segnalazioni
+		AutoHidden: ctx.Query("auto_hidden") == "true",
+		// ?pii_flagged=true limita agli elementi segnalati per dati personali
+		PIIFlagged: ctx.Query("pii_flagged") == "true",
+		// ?mentions=true limita agli elementi che nominano studenti reali
+		Mentions: ctx.Query("mentions") == "true",
+		// ?burst=true limita agli elementi inviati in raffica dallo stesso autore
+		Burst: ctx.Query("burst") == "true",
+		// ?resubmissions=true limita ai reinvii di contenuti già rifiutati
+		Resubmissions: ctx.Query("resubmissions") == "true",
+	}
+	// ?held=true limita agli elementi trattenuti per shadow-ban, esclusi
+	// altrimenti dalla coda in attesa
+	if ctx.Query("held") == "true" {
+		params.Held = HELD_ONLY
+	}
+	// ?unclaimed=true nasconde gli elementi riservati da altri moderatori
+	if ctx.Query("unclaimed") == "true" {
+		moderatorId := ctx.GetInt("moderator_id")
+		params.UnclaimedFor = &moderatorId
+	}
+	var ok bool
+
+	if v := ctx.Query("sort"); v != "" {
+		if _, exists := CONTENT_SORTS[v]; !exists {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_sort",
+				Msg:    "sort must be one of: newest, oldest, most_reported, most_liked, priority, publish_at",
+			})
+			return params, false
+		}
+		params.Sort = v
+	}
+
+	if v := ctx.Query("page_size"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MAX_PAGE_SIZE {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_page_size",
+				Msg:    "page_size must be between 1 and " + strconv.Itoa(MAX_PAGE_SIZE),
+			})
+			return params, false
+		}
+		params.Limit = limit
+	}
+
+	if v := ctx.Query("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil || cursor.Sort != params.Sort {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{
+				Status: "error",
+				Error:  "invalid_cursor",
+				Msg:    "cursor is malformed or belongs to a different sort order",
+			})
+			return params, false
+		}
+		params.Cursor = cursor
+	}
+
+	if params.Status != "" && !validContentStatuses[params.Status] {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_status",
+			Msg:    "Status must be: received, approved, or rejected",
+		})
+		return params, false
+	}
+
+	if params.CityID, ok = queryInt(ctx, "city_id"); !ok {
+		return params, false
+	}
+	if params.SchoolID, ok = queryInt(ctx, "school_id"); !ok {
+		return params, false
+	}
+	if params.CreatorID, ok = queryInt(ctx, "creator_id"); !ok {
+		return params, false
+	}
+	if params.MinReports, ok = queryInt(ctx, "min_reports"); !ok {
+		return params, false
+	}
+	// ?dup_group=N limita ai quasi duplicati di un gruppo
+	if params.DupGroup, ok = queryInt(ctx, "dup_group"); !ok {
+		return params, false
+	}
+	if params.From, ok = queryTime(ctx, "from"); !ok {
+		return params, false
+	}
+	if params.To, ok = queryTime(ctx, "to"); !ok {
+		return params, false
+	}
+
+	return params, true
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 3, 10, 15, 4, 5, 123456000, time.UTC)
+	tests := []struct {
+		name   string
+		cursor ContentCursor
+	}{
+		{"più recenti", ContentCursor{Sort: "newest", Timestamp: ts, ID: 42}},
+		{"più segnalati", ContentCursor{Sort: "most_reported", Key: 7, Timestamp: ts, ID: 1}},
+		{"pubblicazione non programmata", ContentCursor{Sort: "publish_at", Key: math.MaxInt64, Timestamp: ts, ID: 3}},
+		{"fuso orario", ContentCursor{Sort: "oldest", Timestamp: ts.In(time.FixedZone("CET", 3600)), ID: 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodeCursor(tt.cursor)
+			decoded, err := decodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q): %v", encoded, err)
+			}
+			if decoded.Sort != tt.cursor.Sort || decoded.Key != tt.cursor.Key || decoded.ID != tt.cursor.ID ||
+				!decoded.Timestamp.Equal(tt.cursor.Timestamp) {
+				t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", tt.cursor, *decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"non base64", "not a cursor!"},
+		{"base64 standard con padding", base64.StdEncoding.EncodeToString([]byte(`{"s":"newest"}`))},
+		{"json non valido", base64.RawURLEncoding.EncodeToString([]byte(`{"s":`))},
+		{"tipo sbagliato", base64.RawURLEncoding.EncodeToString([]byte(`{"i":"uno"}`))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := decodeCursor(tt.value); err == nil {
+				t.Errorf("decodeCursor(%q) = %+v, want error", tt.value, *cursor)
+			}
+		})
+	}
+}
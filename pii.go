@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	PII_SCAN_INTERVAL = time.Minute
+	PII_SCAN_BATCH    = 500
+)
+
+// Tipi di dato personale riconosciuti
+const (
+	PII_PHONE          = "phone"
+	PII_EMAIL          = "email"
+	PII_SOCIAL_HANDLE  = "social_handle"
+	PII_CODICE_FISCALE = "codice_fiscale"
+	PII_ADDRESS        = "address"
+)
+
+var validPIITypes = map[string]bool{
+	PII_PHONE: true, PII_EMAIL: true, PII_SOCIAL_HANDLE: true, PII_CODICE_FISCALE: true, PII_ADDRESS: true,
+}
+
+// piiDetector riconosce un tipo di dato; check (facoltativo) scarta i falsi
+// positivi che la regex da sola non può escludere
+type piiDetector struct {
+	Type  string
+	re    *regexp.Regexp
+	check func(text string, start, end int) bool
+}
+
+// Cellulari (3xx) e fissi (0x...), con prefisso internazionale facoltativo
+// e separatori spazio, punto o trattino
+var phoneRe = regexp.MustCompile(`(?:(?:\+|00)\s?39[\s.-]?)?(?:3\d{2}[\s.-]?\d{3}[\s.-]?\d{2}[\s.-]?\d{1,2}|0\d{1,3}[\s./-]?\d{2,4}[\s.-]?\d{2,4}(?:[\s.-]?\d{1,4})?)`)
+
+// Email, anche scritte come "nome (at) gmail (dot) com"
+var emailRe = regexp.MustCompile(`(?i)[a-z0-9._%+-]+(?:@|\s?[\(\[]at[\)\]]\s?)[a-z0-9-]+(?:(?:\.|\s?[\(\[]dot[\)\]]\s?)[a-z0-9-]+)*(?:\.|\s?[\(\[]dot[\)\]]\s?)[a-z]{2,}`)
+
+// @nomeutente oppure "ig: nomeutente", "tiktok = nomeutente", ...
+var handleRe = regexp.MustCompile(`(?i)(?:\b(?:instagram|insta|ig|tiktok|snapchat|snap|telegram|tg|twitter)\s*[:=]\s*@?[a-z0-9._]{3,30}|@[a-z0-9._]{3,30})`)
+
+// Codice fiscale, comprese le lettere di omocodia al posto delle cifre
+var codiceFiscaleRe = regexp.MustCompile(`(?i)\b[a-z]{6}[0-9lmnp-v]{2}[abcdehlmprst][0-9lmnp-v]{2}[a-z][0-9lmnp-v]{3}[a-z]\b`)
+
+// Indirizzi con numero civico: "via Roma 12", "p.zza Garibaldi, n. 3/b"
+var addressRe = regexp.MustCompile(`(?i)\b(?:via|viale|v\.le|piazza|p\.zza|piazzale|corso|c\.so|largo|vicolo|strada|contrada|borgo|lungomare)\s+(?:[\p{L}'.]+\s+){0,3}[\p{L}'.]+,?\s*(?:n\.?\s*|n°\s*)?\d{1,4}(?:\s?/\s?[a-z]\b|[a-z]\b)?`)
+
+var piiDetectors = []piiDetector{
+	{Type: PII_EMAIL, re: emailRe},
+	{Type: PII_CODICE_FISCALE, re: codiceFiscaleRe, check: checkCodiceFiscale},
+	{Type: PII_PHONE, re: phoneRe, check: checkPhone},
+	{Type: PII_SOCIAL_HANDLE, re: handleRe, check: checkHandle},
+	{Type: PII_ADDRESS, re: addressRe, check: checkAddress},
+}
+
+// checkPhone esclude numeri attaccati ad altre cifre e sequenze troppo corte
+// (date come 03.12.2024 hanno 8 cifre)
+func checkPhone(text string, start, end int) bool {
+	if start > 0 && (isDigitByte(text[start-1]) || text[start-1] == '+') {
+		return false
+	}
+	if end < len(text) && isDigitByte(text[end]) {
+		return false
+	}
+	match := text[start:end]
+	international := strings.HasPrefix(match, "+") || strings.HasPrefix(match, "00")
+	digits := 0
+	for i := 0; i < len(match); i++ {
+		if isDigitByte(match[i]) {
+			digits++
+		}
+	}
+	if international {
+		digits -= 2 // 39
+		if strings.HasPrefix(match, "00") {
+			digits -= 2
+		}
+		return digits >= 6 && digits <= 11
+	}
+	return digits >= 9 && digits <= 11
+}
+
+// checkHandle scarta la parte dopo la @ di un indirizzo email
+func checkHandle(text string, start, end int) bool {
+	if text[start] != '@' || start == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:start])
+	return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_')
+}
+
+// checkAddress richiede che il civico non sia seguito da altre cifre
+func checkAddress(text string, start, end int) bool {
+	return end >= len(text) || !isDigitByte(text[end])
+}
+
+func isDigitByte(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// Valori dei caratteri in posizione dispari per il carattere di controllo
+var cfOddValues = map[rune]int{
+	'0': 1, '1': 0, '2': 5, '3': 7, '4': 9, '5': 13, '6': 15, '7': 17, '8': 19, '9': 21,
+	'A': 1, 'B': 0, 'C': 5, 'D': 7, 'E': 9, 'F': 13, 'G': 15, 'H': 17, 'I': 19, 'J': 21,
+	'K': 2, 'L': 4, 'M': 18, 'N': 20, 'O': 11, 'P': 3, 'Q': 6, 'R': 8, 'S': 12, 'T': 14,
+	'U': 16, 'V': 10, 'W': 22, 'X': 25, 'Y': 24, 'Z': 23,
+}
+
+// checkCodiceFiscale verifica il carattere di controllo, così una parola
+// qualsiasi di 16 caratteri non viene scambiata per un codice fiscale
+func checkCodiceFiscale(text string, start, end int) bool {
+	code := []rune(strings.ToUpper(text[start:end]))
+	sum := 0
+	for i, r := range code[:15] {
+		if i%2 == 0 {
+			sum += cfOddValues[r]
+		} else if r >= '0' && r <= '9' {
+			sum += int(r - '0')
+		} else {
+			sum += int(r - 'A')
+		}
+	}
+	return code[15] == rune('A'+sum%26)
+}
+
+// detectPII restituisce i dati personali trovati nel testo, con le posizioni
+// in caratteri Unicode. Se due rilevamenti si sovrappongono vale il primo
+// detector dell'elenco (un'email non è anche un handle).
+func detectPII(text string) []PIIMatch {
+	type span struct {
+		typ        string
+		start, end int // byte
+	}
+	var spans []span
+	for _, d := range piiDetectors {
+		for _, loc := range d.re.FindAllStringIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			// Il punto finale è quasi sempre quello della frase
+			for end > start && text[end-1] == '.' {
+				end--
+			}
+			if d.check != nil && !d.check(text, start, end) {
+				continue
+			}
+			overlaps := false
+			for _, s := range spans {
+				if start < s.end && s.start < end {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				spans = append(spans, span{d.Type, start, end})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	matches := make([]PIIMatch, 0, len(spans))
+	for _, s := range spans {
+		start := utf8.RuneCountInString(text[:s.start])
+		matches = append(matches, PIIMatch{
+			Type:  s.typ,
+			Start: start,
+			End:   start + utf8.RuneCountInString(text[s.start:s.end]),
+			Text:  text[s.start:s.end],
+		})
+	}
+	return matches
+}
+
+// annotatePII allega i dati personali trovati agli elementi di una lista
+func annotatePII(items []ContentItem) {
+	for i := range items {
+		items[i].PIIMatches = detectPII(items[i].Content)
+	}
+}
+
+// piiFlagTypes restituisce i tipi trovati che la configurazione segnala
+// automaticamente, senza ripetizioni e in ordine
+func piiFlagTypes(matches []PIIMatch) []string {
+	seen := map[string]bool{}
+	var types []string
+	for _, m := range matches {
+		if seen[m.Type] {
+			continue
+		}
+		seen[m.Type] = true
+		for _, t := range CONF.PII_AUTO_FLAG {
+			if t == m.Type {
+				types = append(types, m.Type)
+				break
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// flagPIIContent controlla gli elementi in attesa o segnalati non ancora
+// esaminati e segnala quelli con dati personali dei tipi configurati, con
+// una voce di audit di sistema. Restituisce quanti elementi ha segnalato.
+func flagPIIContent(db *pgx.Conn) (int, error) {
+	flagged := 0
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		unchecked, err := QueryPIIUnchecked(db, kind, PII_SCAN_BATCH)
+		if err != nil {
+			return flagged, err
+		}
+		for _, p := range unchecked {
+			types := piiFlagTypes(detectPII(p.Content))
+			if len(types) == 0 {
+				if err := MarkPIIChecked(db, kind, p.ID, nil); err != nil {
+					return flagged, err
+				}
+				continue
+			}
+			entry := AuditEntry{
+				Action:     "pii_flag",
+				TargetType: kind.Name,
+				TargetID:   p.ID,
+				ActorRole:  ACTOR_SYSTEM,
+			}
+			err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+				if err := MarkPIIChecked(tx, kind, p.ID, types); err != nil {
+					return nil, nil, err
+				}
+				return nil, map[string]any{"pii_types": types}, nil
+			})
+			if err != nil {
+				println("PII flag error ("+kind.Name+"):", err.Error())
+				continue
+			}
+			flagged++
+		}
+	}
+	return flagged, nil
+}
+
+// startPIIScanner avvia in background la segnalazione automatica; senza tipi
+// configurati in pii_auto_flag non fa nulla
+func startPIIScanner() {
+	if len(CONF.PII_AUTO_FLAG) == 0 {
+		return
+	}
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("PII scan: cannot connect to database:", err.Error())
+			} else {
+				count, err := flagPIIContent(db)
+				if err != nil {
+					println("PII scan error:", err.Error())
+				} else if count > 0 {
+					println("PII scan: flagged", count, "items")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(PII_SCAN_INTERVAL)
+		}
+	}()
+}
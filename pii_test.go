@@ -0,0 +1,94 @@
+package main
+
+import "testing"
+
+func TestCheckCodiceFiscale(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"RSSMRA85T10A562S", true},
+		{"rssmra85t10a562s", true},
+		{"RSSMRA85T10A562T", false},
+		{"RSSMRAURTMLARNSS", false},
+		// Omocodia: le cifre sostituite da lettere cambiano il carattere di controllo
+		{"RSSMRA85T10A56NH", true},
+		{"RSSMRA85T10A56NS", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := checkCodiceFiscale(tt.code, 0, len(tt.code)); got != tt.want {
+				t.Errorf("checkCodiceFiscale(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPhone(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"cellulare", "3331234567", true},
+		{"cellulare con spazi", "333 123 4567", true},
+		{"fisso", "06 1234567", true},
+		{"prefisso +39", "+39 3331234567", true},
+		{"prefisso 0039", "0039 333 1234567", true},
+		{"troppo corto", "0612345", false},
+		{"data", "03.12.2024", false},
+		{"troppe cifre", "333123456789", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkPhone(tt.text, 0, len(tt.text)); got != tt.want {
+				t.Errorf("checkPhone(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	// Numeri attaccati ad altre cifre non sono telefoni
+	if checkPhone("13331234567", 1, 11) {
+		t.Error("checkPhone accetta un numero preceduto da una cifra")
+	}
+	if checkPhone("33312345670", 0, 10) {
+		t.Error("checkPhone accetta un numero seguito da una cifra")
+	}
+}
+
+func TestDetectPII(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []PIIMatch
+	}{
+		{"niente", "ci vediamo domani alle 8", nil},
+		{"cellulare", "scrivimi al 333 123 4567.", []PIIMatch{{Type: PII_PHONE, Start: 12, End: 24, Text: "333 123 4567"}}},
+		{"email", "mario.rossi@gmail.com", []PIIMatch{{Type: PII_EMAIL, Start: 0, End: 21, Text: "mario.rossi@gmail.com"}}},
+		{"email offuscata", "mario (at) gmail (dot) com", []PIIMatch{{Type: PII_EMAIL, Start: 0, End: 26, Text: "mario (at) gmail (dot) com"}}},
+		{"handle", "seguimi su @mario_rossi", []PIIMatch{{Type: PII_SOCIAL_HANDLE, Start: 11, End: 23, Text: "@mario_rossi"}}},
+		{"handle con piattaforma", "ig: mario.rossi", []PIIMatch{{Type: PII_SOCIAL_HANDLE, Start: 0, End: 15, Text: "ig: mario.rossi"}}},
+		{"codice fiscale", "CF RSSMRA85T10A562S", []PIIMatch{{Type: PII_CODICE_FISCALE, Start: 3, End: 19, Text: "RSSMRA85T10A562S"}}},
+		{"codice fiscale non valido", "CF RSSMRA85T10A562T", nil},
+		{"indirizzo", "abito in via Roma 12", []PIIMatch{{Type: PII_ADDRESS, Start: 9, End: 20, Text: "via Roma 12"}}},
+		{"data non è un telefono", "il 03.12.2024", nil},
+		{"posizioni in caratteri", "però: 3331234567", []PIIMatch{{Type: PII_PHONE, Start: 6, End: 16, Text: "3331234567"}}},
+		{"più dati in ordine", "@mario_rossi 3331234567", []PIIMatch{
+			{Type: PII_SOCIAL_HANDLE, Start: 0, End: 12, Text: "@mario_rossi"},
+			{Type: PII_PHONE, Start: 13, End: 23, Text: "3331234567"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectPII(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("detectPII(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("dato %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
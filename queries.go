@@ -2,20 +2,115 @@ package main
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// DBTX è soddisfatta sia da *pgx.Conn sia da pgx.Tx: le query che devono
+// poter girare dentro una transazione (es. insieme all'audit) la accettano.
+type DBTX interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// sqlFilter accumula condizioni WHERE con parametri posizionali.
+// Ogni "?" nella condizione viene sostituito con il prossimo $N.
+type sqlFilter struct {
+	conds []string
+	args  []any
+}
+
+func (f *sqlFilter) add(cond string, args ...any) {
+	for _, arg := range args {
+		f.args = append(f.args, arg)
+		cond = strings.Replace(cond, "?", "$"+strconv.Itoa(len(f.args)), 1)
+	}
+	f.conds = append(f.conds, cond)
+}
+
+// arg aggiunge un parametro senza condizione e restituisce il suo segnaposto
+func (f *sqlFilter) arg(value any) string {
+	f.args = append(f.args, value)
+	return "$" + strconv.Itoa(len(f.args))
+}
+
+func (f *sqlFilter) where() string {
+	if len(f.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(f.conds, " AND ")
+}
+
 // ==================== MODERATORS ====================
 
 func QueryModeratorByCredentials(db *pgx.Conn, username, passwdHash string) (pgx.Rows, error) {
 	return db.Query(
 		context.Background(),
-		"SELECT id, username, name FROM moderators WHERE username=$1 AND passwd_hash=$2 LIMIT 1",
+		"SELECT id, username, name, is_admin FROM moderators WHERE username=$1 AND passwd_hash=$2 AND NOT disabled LIMIT 1",
 		username, passwdHash,
 	)
 }
 
+func QueryAllModerators(db *pgx.Conn) (pgx.Rows, error) {
+	return db.Query(
+		context.Background(),
+		"SELECT id, username, name, is_admin, disabled, created_at FROM moderators ORDER BY username",
+	)
+}
+
+func InsertModerator(db *pgx.Conn, username, passwdHash, name string) error {
+	_, err := db.Exec(
+		context.Background(),
+		"INSERT INTO moderators (username, passwd_hash, name) VALUES ($1, $2, $3)",
+		username, passwdHash, name,
+	)
+	return err
+}
+
+func UpdateModeratorPassword(db *pgx.Conn, username, passwdHash string) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		"UPDATE moderators SET passwd_hash=$1 WHERE username=$2",
+		passwdHash, username,
+	))
+}
+
+func SetModeratorDisabled(db *pgx.Conn, username string, disabled bool) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		"UPDATE moderators SET disabled=$1 WHERE username=$2",
+		disabled, username,
+	))
+}
+
+func SetModeratorAdmin(db *pgx.Conn, username string, admin bool) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		"UPDATE moderators SET is_admin=$1 WHERE username=$2",
+		admin, username,
+	))
+}
+
+// QueryModeratorAccess legge lo stato attuale del moderatore, controllato da
+// authMiddleware: disabilitazioni e cambi di is_admin valgono anche per i
+// token già emessi
+func QueryModeratorAccess(db DBTX, moderatorId int) (Moderator, bool, error) {
+	var m Moderator
+	var disabled bool
+	err := db.QueryRow(
+		context.Background(),
+		"SELECT id, username, name, is_admin, disabled FROM moderators WHERE id=$1",
+		moderatorId,
+	).Scan(&m.ID, &m.Username, &m.Name, &m.IsAdmin, &disabled)
+	return m, disabled, err
+}
+
 // ==================== CITIES ====================
 
 func QueryAllCities(db *pgx.Conn) (pgx.Rows, error) {
@@ -35,21 +130,19 @@ func InsertCity(db *pgx.Conn, name, region string) error {
 }
 
 func UpdateCity(db *pgx.Conn, id int, name, region string) error {
-	_, err := db.Exec(
+	return checkAffected(db.Exec(
 		context.Background(),
 		"UPDATE cities SET name=$1, region=$2 WHERE id=$3",
 		name, region, id,
-	)
-	return err
+	))
 }
 
 func DeleteCity(db *pgx.Conn, id int) error {
-	_, err := db.Exec(
+	return checkAffected(db.Exec(
 		context.Background(),
 		"DELETE FROM cities WHERE id=$1",
 		id,
-	)
-	return err
+	))
 }
 
 // ==================== SCHOOLS ====================
@@ -86,215 +179,19 @@ func InsertSchool(db *pgx.Conn, name string, cityId int, emailDomain string) err
 }
 
 func UpdateSchool(db *pgx.Conn, id int, name, emailDomain string) error {
-	_, err := db.Exec(
+	return checkAffected(db.Exec(
 		context.Background(),
 		"UPDATE schools SET name=$1, email_domain=$2 WHERE id=$3",
 		name, emailDomain, id,
-	)
-	return err
+	))
 }
 
 func DeleteSchool(db *pgx.Conn, id int) error {
-	_, err := db.Exec(
+	return checkAffected(db.Exec(
 		context.Background(),
 		"DELETE FROM schools WHERE id=$1",
 		id,
-	)
-	return err
-}
-
-// ==================== POSTS ====================
-
-func QueryPendingPosts(db *pgx.Conn) (pgx.Rows, error) {
-	return db.Query(
-		context.Background(),
-		`SELECT p.id, p.content, p.creator, p.creation_timestamp, p.likes_count,
-		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
-		        s.name as school_name, c.name as city_name
-		 FROM post p
-		 JOIN users u ON p.creator = u.id
-		 LEFT JOIN schools s ON u.school = s.id
-		 LEFT JOIN cities c ON s.city = c.id
-		 WHERE p.status = (SELECT id FROM submit_status WHERE description='received')
-		 ORDER BY p.creation_timestamp DESC`,
-	)
-}
-
-func QueryReportedPosts(db *pgx.Conn) (pgx.Rows, error) {
-	return db.Query(
-		context.Background(),
-		`SELECT p.id, p.content, p.creator, p.creation_timestamp, p.likes_count,
-		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
-		        s.name as school_name, c.name as city_name,
-		        COUNT(rp.id) as report_count
-		 FROM post p
-		 JOIN users u ON p.creator = u.id
-		 LEFT JOIN schools s ON u.school = s.id
-		 LEFT JOIN cities c ON s.city = c.id
-		 JOIN reported_post rp ON p.id = rp.post_id
-		 GROUP BY p.id, p.content, p.creator, p.creation_timestamp, p.likes_count,
-		          u.first_name, u.last_name, u.email, s.name, c.name
-		 ORDER BY report_count DESC, p.creation_timestamp DESC`,
-	)
-}
-
-func ApprovePost(db *pgx.Conn, postId int) error {
-	_, err := db.Exec(
-		context.Background(),
-		`UPDATE post SET status = (SELECT id FROM submit_status WHERE description='approved'),
-		                 approval_timestamp = NOW()
-		 WHERE id = $1`,
-		postId,
-	)
-	return err
-}
-
-func RejectPost(db *pgx.Conn, postId int) error {
-	_, err := db.Exec(
-		context.Background(),
-		`UPDATE post SET status = (SELECT id FROM submit_status WHERE description='rejected')
-		 WHERE id = $1`,
-		postId,
-	)
-	return err
-}
-
-func DeletePostById(db *pgx.Conn, postId int) error {
-	// First delete likes
-	db.Exec(context.Background(), "DELETE FROM post_like WHERE post_id = $1", postId)
-	// Then delete reports
-	db.Exec(context.Background(), "DELETE FROM reported_post WHERE post_id = $1", postId)
-	// Finally delete the post
-	_, err := db.Exec(context.Background(), "DELETE FROM post WHERE id = $1", postId)
-	return err
-}
-
-func QueryAllPosts(db *pgx.Conn) (pgx.Rows, error) {
-	return db.Query(
-		context.Background(),
-		`SELECT p.id, p.content, p.creator, p.creation_timestamp, p.likes_count,
-		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
-		        s.name as school_name, c.name as city_name,
-		        ss.description as status
-		 FROM post p
-		 JOIN users u ON p.creator = u.id
-		 LEFT JOIN schools s ON u.school = s.id
-		 LEFT JOIN cities c ON s.city = c.id
-		 JOIN submit_status ss ON p.status = ss.id
-		 ORDER BY p.creation_timestamp DESC`,
-	)
-}
-
-func SetPostStatus(db *pgx.Conn, postId int, status string) error {
-	_, err := db.Exec(
-		context.Background(),
-		`UPDATE post SET status = (SELECT id FROM submit_status WHERE description=$1)
-		 WHERE id = $2`,
-		status, postId,
-	)
-	return err
-}
-
-// ==================== SPOTTED ====================
-
-func QueryPendingSpotted(db *pgx.Conn) (pgx.Rows, error) {
-	return db.Query(
-		context.Background(),
-		`SELECT sp.id, sp.content, sp.creator, sp.creation_timestamp, sp.likes_count,
-		        sp.visibility, COALESCE(sp.color, '#6366f1'),
-		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
-		        s.name as school_name, c.name as city_name,
-		        sv.description as visibility_desc
-		 FROM spotted sp
-		 JOIN users u ON sp.creator = u.id
-		 LEFT JOIN schools s ON u.school = s.id
-		 LEFT JOIN cities c ON s.city = c.id
-		 JOIN spotted_visibility sv ON sp.visibility = sv.id
-		 WHERE sp.status = (SELECT id FROM submit_status WHERE description='received')
-		 ORDER BY sp.creation_timestamp DESC`,
-	)
-}
-
-func QueryReportedSpotted(db *pgx.Conn) (pgx.Rows, error) {
-	return db.Query(
-		context.Background(),
-		`SELECT sp.id, sp.content, sp.creator, sp.creation_timestamp, sp.likes_count,
-		        sp.visibility, COALESCE(sp.color, '#6366f1'),
-		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
-		        s.name as school_name, c.name as city_name,
-		        sv.description as visibility_desc,
-		        COUNT(rs.id) as report_count
-		 FROM spotted sp
-		 JOIN users u ON sp.creator = u.id
-		 LEFT JOIN schools s ON u.school = s.id
-		 LEFT JOIN cities c ON s.city = c.id
-		 JOIN spotted_visibility sv ON sp.visibility = sv.id
-		 JOIN reported_spotted rs ON sp.id = rs.spotted_id
-		 GROUP BY sp.id, sp.content, sp.creator, sp.creation_timestamp, sp.likes_count,
-		          sp.visibility, sp.color, u.first_name, u.last_name, u.email,
-		          s.name, c.name, sv.description
-		 ORDER BY report_count DESC, sp.creation_timestamp DESC`,
-	)
-}
-
-func ApproveSpotted(db *pgx.Conn, spottedId int) error {
-	_, err := db.Exec(
-		context.Background(),
-		`UPDATE spotted SET status = (SELECT id FROM submit_status WHERE description='approved'),
-		                    approval_timestamp = NOW()
-		 WHERE id = $1`,
-		spottedId,
-	)
-	return err
-}
-
-func RejectSpotted(db *pgx.Conn, spottedId int) error {
-	_, err := db.Exec(
-		context.Background(),
-		`UPDATE spotted SET status = (SELECT id FROM submit_status WHERE description='rejected')
-		 WHERE id = $1`,
-		spottedId,
-	)
-	return err
-}
-
-func DeleteSpottedById(db *pgx.Conn, spottedId int) error {
-	// First delete likes
-	db.Exec(context.Background(), "DELETE FROM spotted_like WHERE spotted_id = $1", spottedId)
-	// Then delete reports
-	db.Exec(context.Background(), "DELETE FROM reported_spotted WHERE spotted_id = $1", spottedId)
-	// Finally delete the spotted
-	_, err := db.Exec(context.Background(), "DELETE FROM spotted WHERE id = $1", spottedId)
-	return err
-}
-
-func QueryAllSpotted(db *pgx.Conn) (pgx.Rows, error) {
-	return db.Query(
-		context.Background(),
-		`SELECT sp.id, sp.content, sp.creator, sp.creation_timestamp, sp.likes_count,
-		        sp.visibility, COALESCE(sp.color, '#6366f1'),
-		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
-		        s.name as school_name, c.name as city_name,
-		        sv.description as visibility_desc,
-		        ss.description as status
-		 FROM spotted sp
-		 JOIN users u ON sp.creator = u.id
-		 LEFT JOIN schools s ON u.school = s.id
-		 LEFT JOIN cities c ON s.city = c.id
-		 JOIN spotted_visibility sv ON sp.visibility = sv.id
-		 JOIN submit_status ss ON sp.status = ss.id
-		 ORDER BY sp.creation_timestamp DESC`,
-	)
-}
-
-func SetSpottedStatus(db *pgx.Conn, spottedId int, status string) error {
-	_, err := db.Exec(
-		context.Background(),
-		`UPDATE spotted SET status = (SELECT id FROM submit_status WHERE description=$1)
-		 WHERE id = $2`,
-		status, spottedId,
-	)
-	return err
+	))
 }
 
 // ==================== USERS ====================
@@ -306,7 +203,8 @@ func SearchUsers(db *pgx.Conn, searchTerm string) (pgx.Rows, error) {
 		`SELECT u.id, COALESCE(u.email, ''), u.personal_email,
 		        COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
 		        COALESCE(ur.description, 'user') as role,
-		        s.name as school_name, c.name as city_name
+		        s.name as school_name, c.name as city_name,
+		        u.suspended_until, u.banned_at, u.shadow_banned_at
 		 FROM users u
 		 LEFT JOIN user_role ur ON u.user_role = ur.id
 		 LEFT JOIN users_to_school_cities utsc ON u.id = utsc.user_id
@@ -322,14 +220,26 @@ func SearchUsers(db *pgx.Conn, searchTerm string) (pgx.Rows, error) {
 	)
 }
 
-func SetUserRole(db *pgx.Conn, userId int, role string) error {
-	_, err := db.Exec(
+func QueryUserRole(db DBTX, userId int) (string, error) {
+	var role string
+	err := db.QueryRow(
+		context.Background(),
+		`SELECT COALESCE(ur.description, 'user')
+		 FROM users u
+		 LEFT JOIN user_role ur ON u.user_role = ur.id
+		 WHERE u.id = $1`,
+		userId,
+	).Scan(&role)
+	return role, err
+}
+
+func SetUserRole(db DBTX, userId int, role string) error {
+	return checkAffected(db.Exec(
 		context.Background(),
 		`UPDATE users SET user_role = (SELECT id FROM user_role WHERE description = $1)
 		 WHERE id = $2`,
 		role, userId,
-	)
-	return err
+	))
 }
 
 func GetUserById(db *pgx.Conn, userId int) (pgx.Rows, error) {
@@ -338,7 +248,8 @@ func GetUserById(db *pgx.Conn, userId int) (pgx.Rows, error) {
 		`SELECT u.id, COALESCE(u.email, ''), u.personal_email,
 		        COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
 		        COALESCE(ur.description, 'user') as role,
-		        s.name as school_name, c.name as city_name
+		        s.name as school_name, c.name as city_name,
+		        u.suspended_until, u.banned_at, u.shadow_banned_at
 		 FROM users u
 		 LEFT JOIN user_role ur ON u.user_role = ur.id
 		 LEFT JOIN users_to_school_cities utsc ON u.id = utsc.user_id
@@ -349,6 +260,78 @@ func GetUserById(db *pgx.Conn, userId int) (pgx.Rows, error) {
 	)
 }
 
+// QueryUser legge un utente (con la prima scuola associata); ErrNotFound se
+// non esiste
+func QueryUser(db *pgx.Conn, userId int) (User, error) {
+	rows, err := GetUserById(db, userId)
+	if err != nil {
+		return User{}, err
+	}
+	user, err := pgx.CollectOneRow(rows, func(row pgx.CollectableRow) (User, error) {
+		var user User
+		err := row.Scan(
+			&user.ID, &user.Email, &user.PersonalEmail,
+			&user.FirstName, &user.LastName, &user.Role,
+			&user.SchoolName, &user.CityName,
+			&user.SuspendedUntil, &user.BannedAt, &user.ShadowBannedAt,
+		)
+		return user, err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user, ErrNotFound
+	}
+	return user, err
+}
+
+// ==================== REJECTION REASONS ====================
+
+func QueryRejectionReasons(db *pgx.Conn, includeInactive bool) (pgx.Rows, error) {
+	return db.Query(
+		context.Background(),
+		`SELECT code, label, description, active FROM rejection_reasons
+		 WHERE active OR $1
+		 ORDER BY active DESC, label`,
+		includeInactive,
+	)
+}
+
+func QueryRejectionReason(db DBTX, code string) (RejectionReason, error) {
+	var r RejectionReason
+	err := db.QueryRow(
+		context.Background(),
+		"SELECT code, label, description, active FROM rejection_reasons WHERE code = $1",
+		code,
+	).Scan(&r.Code, &r.Label, &r.Description, &r.Active)
+	return r, err
+}
+
+func InsertRejectionReason(db *pgx.Conn, code, label, description string) error {
+	_, err := db.Exec(
+		context.Background(),
+		"INSERT INTO rejection_reasons (code, label, description) VALUES ($1, $2, $3)",
+		code, label, description,
+	)
+	return err
+}
+
+func UpdateRejectionReason(db *pgx.Conn, code, label, description string, active bool) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		"UPDATE rejection_reasons SET label = $2, description = $3, active = $4 WHERE code = $1",
+		code, label, description, active,
+	))
+}
+
+// DeactivateRejectionReason nasconde il motivo dai nuovi rifiuti; gli elementi
+// già rifiutati con quel codice lo mantengono.
+func DeactivateRejectionReason(db *pgx.Conn, code string) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		"UPDATE rejection_reasons SET active = FALSE WHERE code = $1",
+		code,
+	))
+}
+
 // ==================== STATISTICS ====================
 
 // Totali generali
@@ -491,3 +474,24 @@ func QueryTopSchools(db *pgx.Conn, limit int) (pgx.Rows, error) {
 		limit,
 	)
 }
+
+// Elementi rifiutati per motivo (post e spotted)
+func QueryRejectionStats(db *pgx.Conn) (pgx.Rows, error) {
+	return db.Query(
+		context.Background(),
+		`SELECT
+			x.reason, COALESCE(rr.label, 'Senza motivo') as label,
+			COUNT(*) FILTER (WHERE x.kind = 'post') as post_count,
+			COUNT(*) FILTER (WHERE x.kind = 'spotted') as spotted_count
+		 FROM (
+			SELECT 'post' as kind, rejection_reason as reason FROM post
+			 WHERE status = (SELECT id FROM submit_status WHERE description='rejected')
+			UNION ALL
+			SELECT 'spotted', rejection_reason FROM spotted
+			 WHERE status = (SELECT id FROM submit_status WHERE description='rejected')
+		 ) x
+		 LEFT JOIN rejection_reasons rr ON x.reason = rr.code
+		 GROUP BY x.reason, rr.label
+		 ORDER BY COUNT(*) DESC`,
+	)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== AUDIT ====================
+
+func InsertAuditEntry(db DBTX, entry AuditEntry) error {
+	_, err := db.Exec(
+		context.Background(),
+		`INSERT INTO moderation_audit
+		    (actor_id, actor_role, action, target_type, target_id, before_value, after_value, ip, request_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		entry.ActorID, entry.ActorRole, entry.Action, entry.TargetType, entry.TargetID,
+		entry.Before, entry.After, entry.IP, entry.RequestID,
+	)
+	return err
+}
+
+// AuditFilter raccoglie i filtri opzionali della lista di audit
+type AuditFilter struct {
+	ActorID    *int
+	Action     string
+	TargetType string
+	TargetID   *int
+	From       *time.Time
+	To         *time.Time
+}
+
+func (f AuditFilter) sql() sqlFilter {
+	var w sqlFilter
+	if f.ActorID != nil {
+		w.add("a.actor_id = ?", *f.ActorID)
+	}
+	if f.Action != "" {
+		w.add("a.action = ?", f.Action)
+	}
+	if f.TargetType != "" {
+		w.add("a.target_type = ?", f.TargetType)
+	}
+	if f.TargetID != nil {
+		w.add("a.target_id = ?", *f.TargetID)
+	}
+	if f.From != nil {
+		w.add("a.created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		w.add("a.created_at < ?", *f.To)
+	}
+	return w
+}
+
+func CountAuditEntries(db *pgx.Conn, filter AuditFilter) (int, error) {
+	w := filter.sql()
+	var total int
+	err := db.QueryRow(
+		context.Background(),
+		"SELECT COUNT(*) FROM moderation_audit a "+w.where(),
+		w.args...,
+	).Scan(&total)
+	return total, err
+}
+
+func QueryAuditEntries(db *pgx.Conn, filter AuditFilter, limit, offset int) (pgx.Rows, error) {
+	w := filter.sql()
+	query := `SELECT a.id, a.created_at, a.actor_id, a.actor_role, m.name,
+	        a.action, a.target_type, a.target_id, a.before_value, a.after_value,
+	        a.ip, a.request_id
+	 FROM moderation_audit a
+	 LEFT JOIN moderators m ON a.actor_id = m.id
+	 ` + w.where() + `
+	 ORDER BY a.created_at DESC, a.id DESC
+	 LIMIT ` + w.arg(limit) + ` OFFSET ` + w.arg(offset)
+	return db.Query(context.Background(), query, w.args...)
+}
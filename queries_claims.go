@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== CLAIMS ====================
+
+const CLAIM_SELECT = `SELECT cc.kind, cc.item_id, cc.moderator_id, m.name, cc.claimed_at, cc.expires_at
+ FROM content_claims cc
+ JOIN moderators m ON cc.moderator_id = m.id`
+
+// QueryActiveClaim restituisce la riserva non scaduta di un elemento, o nil
+func QueryActiveClaim(db DBTX, kind *ContentKind, id int) (*ContentClaim, error) {
+	var c ContentClaim
+	err := db.QueryRow(
+		context.Background(),
+		CLAIM_SELECT+" WHERE cc.kind = $1 AND cc.item_id = $2 AND cc.expires_at > NOW()",
+		kind.Name, id,
+	).Scan(&c.Kind, &c.ItemID, &c.ModeratorID, &c.ModeratorName, &c.ClaimedAt, &c.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ClaimNextContent riserva al moderatore fino a count elementi in attesa, dal
+// più vecchio, saltando quelli riservati da altri. Le riserve già sue vengono
+// rinnovate e incluse. Due richieste concorrenti non possono ottenere lo
+// stesso elemento: la seconda trova il conflitto e non lo restituisce.
+func ClaimNextContent(db *pgx.Conn, kind *ContentKind, moderatorId, count, leaseMinutes int) ([]int, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`INSERT INTO content_claims (kind, item_id, moderator_id, claimed_at, expires_at)
+		 SELECT '{kind}', {a}.id, $1, NOW(), NOW() + $3::int * INTERVAL '1 minute'
+		 FROM {t} {a}
+		 LEFT JOIN content_claims cc ON cc.kind = '{kind}' AND cc.item_id = {a}.id
+		 WHERE {a}.status = (SELECT id FROM submit_status WHERE description='received')
+		   AND {a}.held_at IS NULL
+		   AND {a}.publish_at IS NULL
+		   AND (cc.item_id IS NULL OR cc.expires_at <= NOW() OR cc.moderator_id = $1)
+		 ORDER BY {a}.mentioned_students DESC, {a}.creation_timestamp, {a}.id
+		 LIMIT $2
+		 ON CONFLICT (kind, item_id) DO UPDATE
+		 SET moderator_id = EXCLUDED.moderator_id, claimed_at = EXCLUDED.claimed_at, expires_at = EXCLUDED.expires_at
+		 WHERE content_claims.expires_at <= NOW() OR content_claims.moderator_id = EXCLUDED.moderator_id
+		 RETURNING item_id`),
+		moderatorId, count, leaseMinutes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// ClaimContent riserva un singolo elemento in attesa. Restituisce false se è
+// già riservato da un altro moderatore, a meno che steal sia vero;
+// ErrNotFound se l'elemento non esiste (o è nel cestino) ed errNotPending se
+// è già stato deciso.
+func ClaimContent(db DBTX, kind *ContentKind, id, moderatorId, leaseMinutes int, steal bool) (bool, error) {
+	tag, err := db.Exec(
+		context.Background(),
+		kind.sql(`INSERT INTO content_claims (kind, item_id, moderator_id, claimed_at, expires_at)
+		 SELECT $1, $2, $3, NOW(), NOW() + $4::int * INTERVAL '1 minute'
+		 WHERE EXISTS (SELECT 1 FROM {t} WHERE id = $2
+		                 AND status = (SELECT id FROM submit_status WHERE description='received'))
+		 ON CONFLICT (kind, item_id) DO UPDATE
+		 SET moderator_id = EXCLUDED.moderator_id, claimed_at = EXCLUDED.claimed_at, expires_at = EXCLUDED.expires_at
+		 WHERE content_claims.expires_at <= NOW() OR content_claims.moderator_id = EXCLUDED.moderator_id OR $5`),
+		kind.Name, id, moderatorId, leaseMinutes, steal,
+	)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+
+	// Nessuna riga: riservato da altri, oppure l'elemento non è più in attesa
+	var status string
+	err = db.QueryRow(
+		context.Background(),
+		kind.sql(`SELECT ss.description FROM {t} {a}
+		 JOIN submit_status ss ON {a}.status = ss.id
+		 WHERE {a}.id = $1`),
+		id,
+	).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	if status != "received" {
+		return false, errNotPending
+	}
+	return false, nil
+}
+
+// DeleteClaim rimuove la riserva di un elemento, se presente
+func DeleteClaim(db DBTX, kind *ContentKind, id int) error {
+	_, err := db.Exec(
+		context.Background(),
+		"DELETE FROM content_claims WHERE kind = $1 AND item_id = $2",
+		kind.Name, id,
+	)
+	return err
+}
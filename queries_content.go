@@ -0,0 +1,546 @@
+package main
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== CONTENT (post, spotted, ...) ====================
+
+// Numero di segnalazioni aperte (non ancora esaminate) di un elemento;
+// usata sia in SELECT sia nei filtri
+const REPORT_COUNT_EXPR = `(SELECT COUNT(*) FROM {reports} r WHERE r.{fk} = {a}.id AND r.reviewed_at IS NULL)`
+
+// Colonne comuni a tutte le liste di moderazione; l'ordine deve restare
+// allineato a scanContentItem.
+const CONTENT_SELECT = `SELECT {a}.id, {a}.content, {a}.creator, {a}.creation_timestamp, {a}.likes_count,
+        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
+        s.name as school_name, c.name as city_name,
+        ss.description as status,
+        ` + REPORT_COUNT_EXPR + ` as report_count,
+        {a}.rejection_reason, rr.label as rejection_reason_label, {a}.rejection_note,
+        {a}.auto_hidden_at, {a}.pii_flagged_at, {a}.pii_flag_types, {a}.mentioned_students,
+        {a}.dup_group_id, (SELECT COUNT(*) FROM {t} d WHERE d.dup_group_id = {a}.dup_group_id AND d.status = {a}.status) as dup_group_size,
+        {a}.burst_flagged_at, {a}.resubmission_distance, {a}.held_at,
+        {a}.publish_at, {a}.scheduled_by, (SELECT name FROM moderators WHERE id = {a}.scheduled_by) as scheduled_by_name,
+        cc.moderator_id as claimed_by, cm.name as claimed_by_name, cc.expires_at as claim_expires_at,
+        (SELECT MAX(cr.created_at) FROM content_revisions cr
+          WHERE cr.kind = '{kind}' AND cr.item_id = {a}.id AND cr.revision > 1) as edited_at`
+
+const CONTENT_FROM = `
+ FROM {t} {a}
+ JOIN users u ON {a}.creator = u.id
+ LEFT JOIN schools s ON u.school = s.id
+ LEFT JOIN cities c ON s.city = c.id
+ JOIN submit_status ss ON {a}.status = ss.id
+ LEFT JOIN rejection_reasons rr ON {a}.rejection_reason = rr.code
+ LEFT JOIN content_claims cc ON cc.kind = '{kind}' AND cc.item_id = {a}.id AND cc.expires_at > NOW()
+ LEFT JOIN moderators cm ON cc.moderator_id = cm.id`
+
+// contentQuery compone SELECT e FROM comuni con le colonne specifiche del tipo,
+// seguiti da filtri e ordinamento passati dal chiamante.
+func contentQuery(kind *ContentKind, tail string) string {
+	return contentQueryWith(kind, "", tail)
+}
+
+// contentQueryWith è come contentQuery ma aggiunge in coda colonne calcolate,
+// da leggere passando le destinazioni extra a scanContentItem.
+func contentQueryWith(kind *ContentKind, extraSelect, tail string) string {
+	query := CONTENT_SELECT
+	if kind.ExtraColumns != "" {
+		query += ",\n        " + kind.ExtraColumns
+	}
+	if extraSelect != "" {
+		query += ",\n        " + extraSelect
+	}
+	query += CONTENT_FROM
+	if kind.ExtraJoins != "" {
+		query += "\n " + kind.ExtraJoins
+	}
+	return kind.sql(query + "\n " + tail)
+}
+
+// contentCountQuery conta le righe che contentQuery restituirebbe con gli stessi filtri
+func contentCountQuery(kind *ContentKind, tail string) string {
+	query := "SELECT COUNT(*)" + CONTENT_FROM
+	if kind.ExtraJoins != "" {
+		query += "\n " + kind.ExtraJoins
+	}
+	return kind.sql(query + "\n " + tail)
+}
+
+// scanContentItem legge una riga prodotta da contentQuery; extra riceve le
+// eventuali colonne aggiunte con contentQueryWith.
+func scanContentItem(rows pgx.Rows, kind *ContentKind, extra ...any) (ContentItem, error) {
+	item := ContentItem{Kind: kind.Name}
+	dest := []any{
+		&item.ID, &item.Content, &item.CreatorID, &item.CreationTimestamp, &item.LikesCount,
+		&item.CreatorFirstName, &item.CreatorLastName, &item.CreatorEmail,
+		&item.SchoolName, &item.CityName, &item.Status, &item.ReportCount,
+		&item.RejectionReason, &item.RejectionReasonLabel, &item.RejectionNote,
+		&item.AutoHiddenAt, &item.PIIFlaggedAt, &item.PIIFlagTypes, &item.MentionedStudents,
+		&item.DupGroupID, &item.DupGroupSize, &item.BurstFlaggedAt, &item.ResubmissionDistance, &item.HeldAt,
+		&item.PublishAt, &item.ScheduledBy, &item.ScheduledByName,
+		&item.ClaimedBy, &item.ClaimedByName, &item.ClaimExpiresAt,
+		&item.EditedAt,
+	}
+	if kind.HasVisibility {
+		dest = append(dest, &item.Visibility, &item.Color, &item.VisibilityDesc)
+	}
+	dest = append(dest, extra...)
+	err := rows.Scan(dest...)
+	return item, err
+}
+
+// Code di moderazione: determinano i filtri fissi della lista
+const (
+	QUEUE_ALL      = "all"
+	QUEUE_PENDING  = "pending"
+	QUEUE_REPORTED = "reported"
+	// Approvati con pubblicazione programmata, non ancora pubblicati
+	QUEUE_SCHEDULED = "scheduled"
+)
+
+// Elementi trattenuti per shadow-ban: esclusi dalla coda in attesa salvo
+// che si chiedano solo loro (HELD_ONLY) o tutti (HELD_INCLUDE)
+const (
+	HELD_ONLY    = "only"
+	HELD_INCLUDE = "include"
+)
+
+// ContentSort è un ordinamento ammesso. Key è l'espressione SQL principale;
+// a parità si ordina sempre per creation_timestamp e id, che insieme alla
+// chiave formano il cursore di paginazione.
+type ContentSort struct {
+	Key  string
+	Desc bool
+}
+
+// Chiave intera dell'ordinamento per data di pubblicazione (secondi, come
+// time.Unix nel cursore); gli elementi non programmati vanno in fondo
+const PUBLISH_AT_KEY = `COALESCE(FLOOR(EXTRACT(EPOCH FROM {a}.publish_at)), 9223372036854775807)::bigint`
+
+var CONTENT_SORTS = map[string]ContentSort{
+	"newest":        {Desc: true},
+	"oldest":        {Desc: false},
+	"most_reported": {Key: REPORT_COUNT_EXPR, Desc: true},
+	"most_liked":    {Key: "{a}.likes_count", Desc: true},
+	// Prima gli elementi che nominano più studenti reali, poi i più recenti
+	"priority": {Key: "{a}.mentioned_students", Desc: true},
+	// Prima le pubblicazioni programmate più vicine; gli altri elementi in coda
+	"publish_at": {Key: PUBLISH_AT_KEY, Desc: false},
+}
+
+// ContentCursor identifica l'ultimo elemento di una pagina
+type ContentCursor struct {
+	Sort      string    `json:"s"`
+	Key       int64     `json:"k,omitempty"`
+	Timestamp time.Time `json:"t"`
+	ID        int       `json:"i"`
+}
+
+// ContentListParams raccoglie paginazione, filtri e ordinamento di una lista
+type ContentListParams struct {
+	Queue         string
+	Sort          string
+	Limit         int
+	Cursor        *ContentCursor
+	Status        string
+	CityID        *int
+	SchoolID      *int
+	CreatorID     *int
+	From          *time.Time
+	To            *time.Time
+	MinReports    *int
+	AutoHidden    bool
+	PIIFlagged    bool
+	Mentions      bool
+	Burst         bool
+	DupGroup      *int
+	Resubmissions bool
+	Held          string
+	// Se valorizzato esclude gli elementi riservati da moderatori diversi da questo
+	UnclaimedFor *int
+	// Limita la lista a questi ID (elementi appena riservati)
+	IDs []int
+}
+
+// filter costruisce le condizioni WHERE comuni a lista e conteggio (senza cursore)
+func (p ContentListParams) filter() sqlFilter {
+	var w sqlFilter
+	switch p.Queue {
+	case QUEUE_PENDING:
+		w.add("{a}.status = (SELECT id FROM submit_status WHERE description='received')")
+		w.add("{a}.publish_at IS NULL")
+		if p.Held == "" {
+			w.add("{a}.held_at IS NULL")
+		}
+	case QUEUE_SCHEDULED:
+		w.add("{a}.status = (SELECT id FROM submit_status WHERE description='received')")
+		w.add("{a}.publish_at IS NOT NULL")
+	case QUEUE_REPORTED:
+		w.add("EXISTS (SELECT 1 FROM {reports} r WHERE r.{fk} = {a}.id AND r.reviewed_at IS NULL)")
+	}
+	if p.Status != "" {
+		w.add("ss.description = ?", p.Status)
+	}
+	if p.CityID != nil {
+		w.add("c.id = ?", *p.CityID)
+	}
+	if p.SchoolID != nil {
+		w.add("s.id = ?", *p.SchoolID)
+	}
+	if p.CreatorID != nil {
+		w.add("{a}.creator = ?", *p.CreatorID)
+	}
+	if p.From != nil {
+		w.add("{a}.creation_timestamp >= ?", *p.From)
+	}
+	if p.To != nil {
+		w.add("{a}.creation_timestamp < ?", *p.To)
+	}
+	if p.MinReports != nil {
+		w.add(REPORT_COUNT_EXPR+" >= ?", *p.MinReports)
+	}
+	if p.UnclaimedFor != nil {
+		w.add("(cc.moderator_id IS NULL OR cc.moderator_id = ?)", *p.UnclaimedFor)
+	}
+	if p.IDs != nil {
+		w.add("{a}.id = ANY(?)", p.IDs)
+	}
+	if p.DupGroup != nil {
+		w.add("{a}.dup_group_id = ?", *p.DupGroup)
+	}
+	if p.Held == HELD_ONLY {
+		w.add("{a}.held_at IS NOT NULL")
+	}
+	if p.Resubmissions {
+		w.add("{a}.resubmission_of IS NOT NULL")
+	}
+	if p.Burst {
+		w.add("{a}.burst_flagged_at IS NOT NULL")
+	}
+	if p.Mentions {
+		w.add("{a}.mentioned_students > 0")
+	}
+	if p.PIIFlagged {
+		w.add("{a}.pii_flagged_at IS NOT NULL")
+	}
+	if p.AutoHidden {
+		w.add("{a}.auto_hidden_at IS NOT NULL AND {a}.status = (SELECT id FROM submit_status WHERE description='received')")
+	}
+	return w
+}
+
+func CountContentList(db *pgx.Conn, kind *ContentKind, params ContentListParams) (int, error) {
+	w := params.filter()
+	var total int
+	err := db.QueryRow(context.Background(), contentCountQuery(kind, w.where()), w.args...).Scan(&total)
+	return total, err
+}
+
+// QueryContentList restituisce fino a Limit+1 elementi: la riga in più serve
+// solo a capire se esiste una pagina successiva.
+func QueryContentList(db *pgx.Conn, kind *ContentKind, params ContentListParams) (pgx.Rows, error) {
+	w := params.filter()
+	sort := CONTENT_SORTS[params.Sort]
+
+	cmp, dir := ">", "ASC"
+	if sort.Desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	if params.Cursor != nil {
+		if sort.Key != "" {
+			w.add("("+sort.Key+", {a}.creation_timestamp, {a}.id) "+cmp+" (?, ?, ?)",
+				params.Cursor.Key, params.Cursor.Timestamp, params.Cursor.ID)
+		} else {
+			w.add("({a}.creation_timestamp, {a}.id) "+cmp+" (?, ?)",
+				params.Cursor.Timestamp, params.Cursor.ID)
+		}
+	}
+
+	order := "ORDER BY "
+	if sort.Key != "" {
+		order += sort.Key + " " + dir + ", "
+	}
+	order += "{a}.creation_timestamp " + dir + ", {a}.id " + dir
+
+	tail := w.where() + "\n " + order + "\n LIMIT " + w.arg(params.Limit+1)
+	return db.Query(context.Background(), contentQuery(kind, tail), w.args...)
+}
+
+// QueryContentIds restituisce fino a limit ID che soddisfano i filtri della
+// lista, in ordine di creazione. Usata dalle azioni massive con filtro.
+func QueryContentIds(db *pgx.Conn, kind *ContentKind, params ContentListParams, limit int) ([]int, error) {
+	w := params.filter()
+	query := "SELECT {a}.id" + CONTENT_FROM
+	if kind.ExtraJoins != "" {
+		query += "\n " + kind.ExtraJoins
+	}
+	query += "\n " + w.where() + "\n ORDER BY {a}.creation_timestamp, {a}.id\n LIMIT " + w.arg(limit)
+
+	rows, err := db.Query(context.Background(), kind.sql(query), w.args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// Espressione indicizzata da 005_content_search.sql: va tenuta identica
+const CONTENT_TSVECTOR = `to_tsvector('italian_unaccent', COALESCE({a}.content, ''))`
+
+// Evidenziazione degli snippet: <mark> attorno ai termini trovati
+const SEARCH_HEADLINE_OPTS = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10"
+
+// searchFilter aggiunge ai filtri della lista la condizione full-text e
+// restituisce il segnaposto della query di ricerca.
+// websearch_to_tsquery supporta "frasi", -negazioni e OR.
+func searchFilter(params ContentListParams, search string) (sqlFilter, string) {
+	w := params.filter()
+	placeholder := w.arg(search)
+	w.add(CONTENT_TSVECTOR + " @@ websearch_to_tsquery('italian_unaccent', " + placeholder + ")")
+	return w, placeholder
+}
+
+func CountContentSearch(db *pgx.Conn, kind *ContentKind, search string, params ContentListParams) (int, error) {
+	w, _ := searchFilter(params, search)
+	var total int
+	err := db.QueryRow(context.Background(), contentCountQuery(kind, w.where()), w.args...).Scan(&total)
+	return total, err
+}
+
+// QueryContentSearch restituisce i risultati ordinati per rilevanza con
+// rank e snippet evidenziato come colonne aggiuntive.
+func QueryContentSearch(db *pgx.Conn, kind *ContentKind, search string, params ContentListParams, offset int) (pgx.Rows, error) {
+	w, placeholder := searchFilter(params, search)
+	tsquery := "websearch_to_tsquery('italian_unaccent', " + placeholder + ")"
+	extra := "ts_rank_cd(" + CONTENT_TSVECTOR + ", " + tsquery + ") as rank,\n        " +
+		"ts_headline('italian_unaccent', COALESCE({a}.content, ''), " + tsquery + ", '" + SEARCH_HEADLINE_OPTS + "') as snippet"
+	tail := w.where() + `
+	 ORDER BY rank DESC, {a}.creation_timestamp DESC, {a}.id DESC
+	 LIMIT ` + w.arg(params.Limit) + ` OFFSET ` + w.arg(offset)
+	return db.Query(context.Background(), contentQueryWith(kind, extra, tail), w.args...)
+}
+
+// cursorAfter costruisce il cursore che punta dopo l'elemento dato
+func cursorAfter(item ContentItem, sortName string) ContentCursor {
+	cursor := ContentCursor{Sort: sortName, Timestamp: item.CreationTimestamp, ID: item.ID}
+	switch sortName {
+	case "most_reported":
+		cursor.Key = int64(item.ReportCount)
+	case "most_liked":
+		cursor.Key = int64(item.LikesCount)
+	case "priority":
+		cursor.Key = int64(item.MentionedStudents)
+	case "publish_at":
+		cursor.Key = math.MaxInt64
+		if item.PublishAt != nil {
+			cursor.Key = item.PublishAt.Unix()
+		}
+	}
+	return cursor
+}
+
+// QueryContentStatus legge lo stato corrente bloccando la riga fino a fine transazione
+func QueryContentStatus(db DBTX, kind *ContentKind, id int) (string, error) {
+	var status string
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql(`SELECT ss.description FROM {t} {a}
+		 JOIN submit_status ss ON {a}.status = ss.id
+		 WHERE {a}.id = $1
+		 FOR UPDATE OF {a}`),
+		id,
+	).Scan(&status)
+	return status, err
+}
+
+// QueryContentSnapshot restituisce lo stato completo di un elemento per l'audit
+func QueryContentSnapshot(db DBTX, kind *ContentKind, id int) (map[string]any, error) {
+	var status, content string
+	var creator, likes int
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql(`SELECT ss.description, {a}.content, {a}.creator, {a}.likes_count FROM {t} {a}
+		 JOIN submit_status ss ON {a}.status = ss.id
+		 WHERE {a}.id = $1
+		 FOR UPDATE OF {a}`),
+		id,
+	).Scan(&status, &content, &creator, &likes)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"status":      status,
+		"content":     content,
+		"creator_id":  creator,
+		"likes_count": likes,
+	}, nil
+}
+
+// QueryContentRejection restituisce motivo e nota di rifiuto di un elemento
+func QueryContentRejection(db DBTX, kind *ContentKind, id int) (*string, *string, error) {
+	var reason, note *string
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql("SELECT rejection_reason, rejection_note FROM {t} WHERE id = $1"),
+		id,
+	).Scan(&reason, &note)
+	return reason, note, err
+}
+
+// ApproveContent approva l'elemento cancellando un eventuale motivo di rifiuto precedente
+func ApproveContent(db DBTX, kind *ContentKind, id int) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET status = (SELECT id FROM submit_status WHERE description='approved'),
+		                 approval_timestamp = NOW(),
+		                 rejection_reason = NULL, rejection_note = NULL,
+		                 publish_at = NULL, scheduled_by = NULL
+		 WHERE id = $1`),
+		id,
+	))
+}
+
+// RejectContent rifiuta l'elemento; reason e note possono essere nil
+func RejectContent(db DBTX, kind *ContentKind, id int, reason, note *string) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET status = (SELECT id FROM submit_status WHERE description='rejected'),
+		                 rejection_reason = $2, rejection_note = $3,
+		                 publish_at = NULL, scheduled_by = NULL
+		 WHERE id = $1`),
+		id, reason, note,
+	))
+}
+
+// SetContentStatus imposta lo stato; motivo e nota si conservano solo se
+// lo stato è rejected, altrimenti vengono azzerati. Un elemento che torna
+// in attesa va ricontrollato per i termini vietati.
+func SetContentStatus(db DBTX, kind *ContentKind, id int, status string, reason, note *string) error {
+	if status != "rejected" {
+		reason, note = nil, nil
+	}
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET status = (SELECT id FROM submit_status WHERE description=$1),
+		                 rejection_reason = $3, rejection_note = $4,
+		                 publish_at = NULL, scheduled_by = NULL,
+		                 terms_checked_at = CASE WHEN $1 = 'received' THEN NULL ELSE terms_checked_at END
+		 WHERE id = $2`),
+		status, id, reason, note,
+	))
+}
+
+// DeleteContentById elimina l'elemento con like e segnalazioni in un'unica
+// transazione. Restituisce ErrNotFound se l'elemento non esiste.
+func DeleteContentById(db DBTX, kind *ContentKind, id int) (DeleteResult, error) {
+	var result DeleteResult
+
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(context.Background())
+
+	tag, err := tx.Exec(context.Background(), kind.sql("DELETE FROM {likes} WHERE {fk} = $1"), id)
+	if err != nil {
+		return result, err
+	}
+	result.LikesDeleted = tag.RowsAffected()
+
+	tag, err = tx.Exec(context.Background(), kind.sql("DELETE FROM {reports} WHERE {fk} = $1"), id)
+	if err != nil {
+		return result, err
+	}
+	result.ReportsDeleted = tag.RowsAffected()
+
+	tag, err = tx.Exec(context.Background(), kind.sql("DELETE FROM {t} WHERE id = $1"), id)
+	if err != nil {
+		return result, err
+	}
+	if tag.RowsAffected() == 0 {
+		return DeleteResult{}, ErrNotFound
+	}
+
+	return result, tx.Commit(context.Background())
+}
+
+// PendingText è il minimo necessario per controllare il testo di un elemento
+type PendingText struct {
+	ID      int
+	Content string
+}
+
+// QueryTermsUnchecked restituisce fino a limit elementi in attesa non ancora
+// controllati per i termini vietati. Trattenuti e programmati restano fuori:
+// li ha già presi in carico il sistema o un moderatore.
+func QueryTermsUnchecked(db *pgx.Conn, kind *ContentKind, limit int) ([]PendingText, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, COALESCE({a}.content, '') FROM {t} {a}
+		 WHERE {a}.terms_checked_at IS NULL
+		   AND {a}.status = (SELECT id FROM submit_status WHERE description='received')
+		   AND {a}.held_at IS NULL AND {a}.publish_at IS NULL
+		 ORDER BY {a}.id
+		 LIMIT $1`),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (PendingText, error) {
+		var p PendingText
+		err := row.Scan(&p.ID, &p.Content)
+		return p, err
+	})
+}
+
+// QueryPIIUnchecked restituisce fino a limit elementi in attesa o con
+// segnalazioni aperte non ancora controllati per i dati personali
+func QueryPIIUnchecked(db *pgx.Conn, kind *ContentKind, limit int) ([]PendingText, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, COALESCE({a}.content, '') FROM {t} {a}
+		 WHERE {a}.pii_checked_at IS NULL
+		   AND ({a}.status = (SELECT id FROM submit_status WHERE description='received')
+		        OR EXISTS (SELECT 1 FROM {reports} r WHERE r.{fk} = {a}.id AND r.reviewed_at IS NULL))
+		 ORDER BY {a}.id
+		 LIMIT $1`),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (PendingText, error) {
+		var p PendingText
+		err := row.Scan(&p.ID, &p.Content)
+		return p, err
+	})
+}
+
+// MarkTermsChecked registra il controllo dei termini vietati
+func MarkTermsChecked(db DBTX, kind *ContentKind, id int) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql("UPDATE {t} SET terms_checked_at = NOW() WHERE id = $1"),
+		id,
+	))
+}
+
+// MarkPIIChecked registra il controllo dei dati personali; con types non
+// vuoto l'elemento risulta segnalato
+func MarkPIIChecked(db DBTX, kind *ContentKind, id int, types []string) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET pii_checked_at = NOW(),
+		                 pii_flagged_at = CASE WHEN cardinality($2::TEXT[]) > 0 THEN NOW() END,
+		                 pii_flag_types = $2
+		 WHERE id = $1`),
+		id, types,
+	))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== EVENTS ====================
+
+const MODERATION_EVENT_SELECT = `SELECT id, type, kind, item_id, status, school_id, city_id, actor_id, created_at
+ FROM moderation_events`
+
+func scanModerationEvent(row pgx.CollectableRow) (ModerationEvent, error) {
+	var e ModerationEvent
+	err := row.Scan(&e.ID, &e.Type, &e.Kind, &e.ItemID, &e.Status, &e.SchoolID, &e.CityID, &e.ActorID, &e.CreatedAt)
+	return e, err
+}
+
+// QueryEventsAfter restituisce fino a limit eventi successivi ad afterId, in ordine
+func QueryEventsAfter(db *pgx.Conn, afterId int64, limit int) ([]ModerationEvent, error) {
+	rows, err := db.Query(
+		context.Background(),
+		MODERATION_EVENT_SELECT+" WHERE id > $1 ORDER BY id LIMIT $2",
+		afterId, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanModerationEvent)
+}
+
+// QueryLatestEventId restituisce l'ID dell'ultimo evento registrato (0 se nessuno)
+func QueryLatestEventId(db *pgx.Conn) (int64, error) {
+	var id int64
+	err := db.QueryRow(context.Background(), "SELECT COALESCE(MAX(id), 0) FROM moderation_events").Scan(&id)
+	return id, err
+}
+
+// PurgeModerationEvents elimina gli eventi più vecchi di retention; il limite
+// è calcolato dal database, nello stesso fuso di created_at
+func PurgeModerationEvents(db *pgx.Conn, retention time.Duration) error {
+	_, err := db.Exec(
+		context.Background(),
+		"DELETE FROM moderation_events WHERE created_at < NOW() - $1::int * INTERVAL '1 second'",
+		int(retention.Seconds()),
+	)
+	return err
+}
+
+// SetEventActor fa sì che gli eventi generati nella transazione riportino il
+// moderatore che ha agito (vedi 013_moderation_events.sql)
+func SetEventActor(db DBTX, moderatorId int) error {
+	_, err := db.Exec(
+		context.Background(),
+		"SELECT set_config('app.moderator_id', $1, true)",
+		strconv.Itoa(moderatorId),
+	)
+	return err
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== FINGERPRINTS ====================
+
+// FingerprintCandidate è un elemento di cui calcolare l'impronta
+type FingerprintCandidate struct {
+	ID                int
+	Content           string
+	CreatorID         int
+	CreationTimestamp time.Time
+	Status            string
+}
+
+// QueryMissingFingerprints restituisce fino a limit elementi senza impronta,
+// prima quelli in attesa e poi dal più recente
+func QueryMissingFingerprints(db *pgx.Conn, kind *ContentKind, limit int) ([]FingerprintCandidate, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, COALESCE({a}.content, ''), {a}.creator, {a}.creation_timestamp, ss.description
+		 FROM {t} {a}
+		 JOIN submit_status ss ON {a}.status = ss.id
+		 WHERE {a}.simhash IS NULL
+		 ORDER BY ss.description = 'received' DESC, {a}.id DESC
+		 LIMIT $1`),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (FingerprintCandidate, error) {
+		var c FingerprintCandidate
+		err := row.Scan(&c.ID, &c.Content, &c.CreatorID, &c.CreationTimestamp, &c.Status)
+		return c, err
+	})
+}
+
+func SetFingerprint(db DBTX, kind *ContentKind, id int, fingerprint int64) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql("UPDATE {t} SET simhash = $2 WHERE id = $1"),
+		id, fingerprint,
+	))
+}
+
+// DupCandidate è un elemento in attesa con la sua impronta e l'eventuale
+// gruppo di quasi duplicati
+type DupCandidate struct {
+	ID      int
+	Simhash int64
+	Group   *int
+}
+
+// QueryPendingFingerprints restituisce gli elementi in attesa con impronta
+func QueryPendingFingerprints(db *pgx.Conn, kind *ContentKind) ([]DupCandidate, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, {a}.simhash, {a}.dup_group_id FROM {t} {a}
+		 WHERE {a}.status = (SELECT id FROM submit_status WHERE description='received')
+		   AND {a}.simhash IS NOT NULL AND {a}.simhash <> 0
+		 ORDER BY {a}.id`),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (DupCandidate, error) {
+		var c DupCandidate
+		err := row.Scan(&c.ID, &c.Simhash, &c.Group)
+		return c, err
+	})
+}
+
+// SetDupGroups assegna a ogni elemento il gruppo di quasi duplicati indicato
+func SetDupGroups(db DBTX, kind *ContentKind, groups map[int]int) error {
+	ids := make([]int, 0, len(groups))
+	groupIds := make([]int, 0, len(groups))
+	for id, group := range groups {
+		ids = append(ids, id)
+		groupIds = append(groupIds, group)
+	}
+	_, err := db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET dup_group_id = g.group_id
+		 FROM unnest($1::int[], $2::int[]) AS g(id, group_id)
+		 WHERE {t}.id = g.id`),
+		ids, groupIds,
+	)
+	return err
+}
+
+// FlagBurst segna gli elementi in attesa dell'autore creati nella finestra
+// che termina con created, se sono almeno minItems. Restituisce quanti
+// elementi sono stati segnati per la prima volta.
+func FlagBurst(db DBTX, kind *ContentKind, creatorId int, created time.Time, window time.Duration, minItems int) (int64, error) {
+	tag, err := db.Exec(
+		context.Background(),
+		kind.sql(`WITH burst AS (
+		     SELECT {a}.id FROM {t} {a}
+		      WHERE {a}.creator = $1
+		        AND {a}.creation_timestamp BETWEEN $2::timestamp - $3 * INTERVAL '1 second' AND $2
+		        AND {a}.status = (SELECT id FROM submit_status WHERE description='received')
+		 )
+		 UPDATE {t} SET burst_flagged_at = NOW()
+		  WHERE id IN (SELECT id FROM burst)
+		    AND burst_flagged_at IS NULL
+		    AND (SELECT COUNT(*) FROM burst) >= $4`),
+		creatorId, created, int(window.Seconds()), minItems,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// QueryContentFingerprint restituisce il testo di un elemento per confrontarlo
+func QueryContentFingerprint(db DBTX, kind *ContentKind, id int) (string, error) {
+	var content string
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql("SELECT COALESCE(content, '') FROM {t} WHERE id = $1"),
+		id,
+	).Scan(&content)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return content, err
+}
+
+// QuerySimilarContent restituisce gli elementi con impronta entro maxDistance,
+// (opzionalmente con un dato stato), dal più simile, con la distanza come
+// colonna aggiuntiva
+func QuerySimilarContent(db *pgx.Conn, kind *ContentKind, id int, fingerprint int64, status string, maxDistance, limit int) (pgx.Rows, error) {
+	var w sqlFilter
+	distance := fmt.Sprintf(SIMHASH_DISTANCE_EXPR, "{a}.simhash", w.arg(fingerprint))
+	w.add("{a}.simhash IS NOT NULL AND {a}.simhash <> 0")
+	w.add("{a}.id <> ?", id)
+	if status != "" {
+		w.add("ss.description = ?", status)
+	}
+	w.add(distance+" <= ?", maxDistance)
+	tail := w.where() + "\n ORDER BY distance, {a}.creation_timestamp DESC, {a}.id DESC\n LIMIT " + w.arg(limit)
+	return db.Query(context.Background(), contentQueryWith(kind, distance+" as distance", tail), w.args...)
+}
+
+// QueryBursts riassume per autore gli elementi in attesa segnati come
+// raffica creati nelle ultime hours ore, calcolate dal database nello
+// stesso fuso della colonna
+func QueryBursts(db *pgx.Conn, kind *ContentKind, hours int) ([]ContentBurst, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.creator, COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
+		        COUNT(*), MIN({a}.creation_timestamp), MAX({a}.creation_timestamp),
+		        array_agg({a}.id ORDER BY {a}.creation_timestamp, {a}.id)
+		 FROM {t} {a}
+		 JOIN users u ON {a}.creator = u.id
+		 WHERE {a}.burst_flagged_at IS NOT NULL
+		   AND {a}.creation_timestamp >= NOW() - $1::int * INTERVAL '1 hour'
+		   AND {a}.status = (SELECT id FROM submit_status WHERE description='received')
+		 GROUP BY {a}.creator, u.first_name, u.last_name, u.email
+		 ORDER BY COUNT(*) DESC, MAX({a}.creation_timestamp) DESC`),
+		hours,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ContentBurst, error) {
+		var b ContentBurst
+		err := row.Scan(&b.CreatorID, &b.CreatorFirstName, &b.CreatorLastName, &b.CreatorEmail,
+			&b.Items, &b.FirstAt, &b.LastAt, &b.ItemIDs)
+		return b, err
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== MENTIONS ====================
+
+// MentionCandidate è un elemento in attesa da controllare con la scuola dell'autore
+type MentionCandidate struct {
+	ID        int
+	Content   string
+	CreatorID int
+	SchoolID  *int
+}
+
+// QueryMentionUnchecked restituisce fino a limit elementi in attesa non
+// ancora controllati per le menzioni di studenti
+func QueryMentionUnchecked(db *pgx.Conn, kind *ContentKind, limit int) ([]MentionCandidate, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, COALESCE({a}.content, ''), {a}.creator, u.school
+		 FROM {t} {a}
+		 JOIN users u ON {a}.creator = u.id
+		 WHERE {a}.mentions_checked_at IS NULL
+		   AND {a}.status = (SELECT id FROM submit_status WHERE description='received')
+		 ORDER BY {a}.id
+		 LIMIT $1`),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (MentionCandidate, error) {
+		var c MentionCandidate
+		err := row.Scan(&c.ID, &c.Content, &c.CreatorID, &c.SchoolID)
+		return c, err
+	})
+}
+
+// QuerySchoolStudents restituisce nome e cognome degli utenti di una scuola
+func QuerySchoolStudents(db *pgx.Conn, schoolId int) ([]SchoolStudent, error) {
+	rows, err := db.Query(
+		context.Background(),
+		`SELECT id, COALESCE(first_name, ''), COALESCE(last_name, '')
+		 FROM users
+		 WHERE school = $1 AND first_name <> '' AND last_name <> ''`,
+		schoolId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (SchoolStudent, error) {
+		var s SchoolStudent
+		err := row.Scan(&s.ID, &s.FirstName, &s.LastName)
+		return s, err
+	})
+}
+
+// SaveMentions sostituisce le menzioni di un elemento e ne aggiorna il
+// conteggio, segnando l'elemento come controllato
+func SaveMentions(db DBTX, kind *ContentKind, id int, mentions []StudentMention) error {
+	if _, err := db.Exec(
+		context.Background(),
+		"DELETE FROM content_mentions WHERE kind = $1 AND item_id = $2",
+		kind.Name, id,
+	); err != nil {
+		return err
+	}
+
+	students := map[int]bool{}
+	for _, m := range mentions {
+		students[m.UserID] = true
+		if _, err := db.Exec(
+			context.Background(),
+			`INSERT INTO content_mentions (kind, item_id, user_id, start_pos, end_pos, matched_text)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT DO NOTHING`,
+			kind.Name, id, m.UserID, m.Start, m.End, m.Text,
+		); err != nil {
+			return err
+		}
+	}
+
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql("UPDATE {t} SET mentions_checked_at = NOW(), mentioned_students = $2 WHERE id = $1"),
+		id, len(students),
+	))
+}
+
+// QueryContentMentions restituisce le menzioni degli elementi indicati, con
+// i dati attuali degli studenti, raggruppate per elemento
+func QueryContentMentions(db DBTX, kind *ContentKind, ids []int) (map[int][]StudentMention, error) {
+	rows, err := db.Query(
+		context.Background(),
+		`SELECT m.item_id, m.user_id, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+		        COALESCE(u.email, ''), m.start_pos, m.end_pos, m.matched_text
+		 FROM content_mentions m
+		 JOIN users u ON m.user_id = u.id
+		 WHERE m.kind = $1 AND m.item_id = ANY($2)
+		 ORDER BY m.item_id, m.start_pos`,
+		kind.Name, ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mentions := map[int][]StudentMention{}
+	for rows.Next() {
+		var itemId int
+		var m StudentMention
+		if err := rows.Scan(&itemId, &m.UserID, &m.FirstName, &m.LastName, &m.Email, &m.Start, &m.End, &m.Text); err != nil {
+			return nil, err
+		}
+		mentions[itemId] = append(mentions[itemId], m)
+	}
+	return mentions, rows.Err()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== USER PROFILE ====================
+
+// QueryUserCreatedAt restituisce la data di registrazione (se nota)
+func QueryUserCreatedAt(db DBTX, userId int) (*time.Time, error) {
+	var created *time.Time
+	err := db.QueryRow(context.Background(), "SELECT creation_timestamp FROM users WHERE id = $1", userId).Scan(&created)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return created, err
+}
+
+// QueryUserContentStats conta i contenuti di un tipo dell'utente per stato,
+// con like e segnalazioni ricevute, segnalazioni inviate ed elementi nel cestino
+func QueryUserContentStats(db DBTX, kind *ContentKind, userId int) (UserContentStats, error) {
+	var s UserContentStats
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql(`SELECT COUNT(*),
+		        COUNT(*) FILTER (WHERE ss.description = 'received'),
+		        COUNT(*) FILTER (WHERE ss.description = 'approved'),
+		        COUNT(*) FILTER (WHERE ss.description = 'rejected'),
+		        COUNT(*) FILTER (WHERE ss.description = 'received' AND {a}.held_at IS NOT NULL),
+		        COALESCE(SUM({a}.likes_count), 0),
+		        (SELECT COUNT(*) FROM {reports} r JOIN {t} x ON r.{fk} = x.id WHERE x.creator = $1),
+		        (SELECT COUNT(*) FROM {reports} r JOIN {t} x ON r.{fk} = x.id WHERE x.creator = $1 AND r.reviewed_at IS NULL),
+		        (SELECT COUNT(*) FROM {reports} r WHERE r.user_id = $1),
+		        (SELECT COUNT(*) FROM content_trash ct WHERE ct.kind = '{kind}' AND (ct.item->>'creator')::int = $1)
+		 FROM {t} {a}
+		 JOIN submit_status ss ON {a}.status = ss.id
+		 WHERE {a}.creator = $1`),
+		userId,
+	).Scan(&s.Total, &s.Pending, &s.Approved, &s.Rejected, &s.Held, &s.LikesReceived,
+		&s.ReportsReceived, &s.OpenReports, &s.ReportsFiled, &s.Deleted)
+	return s, err
+}
+
+// QueryRoleHistory restituisce i cambi di ruolo dell'utente, dal più recente
+func QueryRoleHistory(db DBTX, userId int) ([]RoleChange, error) {
+	rows, err := db.Query(
+		context.Background(),
+		`SELECT a.created_at, a.before_value->>'role', a.after_value->>'role',
+		        a.actor_id, m.name, a.actor_role
+		 FROM moderation_audit a
+		 LEFT JOIN moderators m ON a.actor_id = m.id
+		 WHERE a.target_type = 'user' AND a.target_id = $1 AND a.action = 'set_role'
+		 ORDER BY a.created_at DESC, a.id DESC`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (RoleChange, error) {
+		var c RoleChange
+		err := row.Scan(&c.ChangedAt, &c.From, &c.To, &c.ModeratorID, &c.ModeratorName, &c.ActorRole)
+		return c, err
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== REPORTS ====================
+
+// QueryContentExists restituisce ErrNotFound se l'elemento non esiste
+func QueryContentExists(db DBTX, kind *ContentKind, id int) error {
+	var exists bool
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql("SELECT EXISTS (SELECT 1 FROM {t} WHERE id = $1)"),
+		id,
+	).Scan(&exists)
+	if err == nil && !exists {
+		return ErrNotFound
+	}
+	return err
+}
+
+// QueryContentReports restituisce le segnalazioni di un elemento, dalla più recente
+func QueryContentReports(db *pgx.Conn, kind *ContentKind, id int) (pgx.Rows, error) {
+	return db.Query(
+		context.Background(),
+		kind.sql(`SELECT r.id, r.user_id,
+		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''), COALESCE(u.email, 'N/A'),
+		        s.name as school_name, r.creation_timestamp, r.reason,
+		        r.reviewed_at, r.reviewed_by, m.name
+		 FROM {reports} r
+		 LEFT JOIN users u ON r.user_id = u.id
+		 LEFT JOIN schools s ON u.school = s.id
+		 LEFT JOIN moderators m ON r.reviewed_by = m.id
+		 WHERE r.{fk} = $1
+		 ORDER BY r.creation_timestamp DESC, r.id DESC`),
+		id,
+	)
+}
+
+// QueryRecentReportCounts conta, per ciascun utente, le segnalazioni inviate
+// nell'ultimo window su tutti i tipi di contenuto; il limite è calcolato
+// dal database, nello stesso fuso della colonna.
+func QueryRecentReportCounts(db *pgx.Conn, userIds []int, window time.Duration) (map[int]int, error) {
+	parts := make([]string, 0, len(CONTENT_KINDS))
+	for _, name := range contentKindNames() {
+		parts = append(parts, CONTENT_KINDS[name].sql(
+			"SELECT user_id FROM {reports} WHERE user_id = ANY($1) AND creation_timestamp >= NOW() - $2::int * INTERVAL '1 second'",
+		))
+	}
+
+	rows, err := db.Query(
+		context.Background(),
+		"SELECT user_id, COUNT(*) FROM ("+strings.Join(parts, " UNION ALL ")+") x GROUP BY user_id",
+		userIds, int(window.Seconds()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int, len(userIds))
+	for rows.Next() {
+		var userId, count int
+		if err := rows.Scan(&userId, &count); err != nil {
+			return nil, err
+		}
+		counts[userId] = count
+	}
+	return counts, rows.Err()
+}
+
+// CountOpenReports conta le segnalazioni non ancora esaminate di un elemento
+func CountOpenReports(db DBTX, kind *ContentKind, id int) (int, error) {
+	var count int
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql("SELECT COUNT(*) FROM {reports} WHERE {fk} = $1 AND reviewed_at IS NULL"),
+		id,
+	).Scan(&count)
+	return count, err
+}
+
+// DismissReports segna come esaminate le segnalazioni aperte di un elemento.
+// reviewedBy è nil per CLI e account statici. Restituisce quante ne ha chiuse.
+func DismissReports(db DBTX, kind *ContentKind, id int, reviewedBy *int) (int64, error) {
+	tag, err := db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {reports} SET reviewed_at = NOW(), reviewed_by = $2
+		 WHERE {fk} = $1 AND reviewed_at IS NULL`),
+		id, reviewedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// AutoHideCandidate è un elemento approvato che ha superato una soglia di segnalazioni
+type AutoHideCandidate struct {
+	ID                int
+	OpenReports       int
+	DistinctReporters int
+}
+
+// QueryAutoHideCandidates restituisce gli elementi approvati le cui
+// segnalazioni aperte (successive all'ultimo occultamento automatico)
+// superano una delle soglie. I segnalatori distinti si contano nelle ultime
+// WINDOW_HOURS ore, calcolate dal database nello stesso fuso della colonna.
+func QueryAutoHideCandidates(db *pgx.Conn, kind *ContentKind, threshold AutoHideThreshold) ([]AutoHideCandidate, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, COUNT(*),
+		        COUNT(DISTINCT r.user_id) FILTER (WHERE r.creation_timestamp >= NOW() - $3::int * INTERVAL '1 hour')
+		 FROM {t} {a}
+		 JOIN {reports} r ON r.{fk} = {a}.id
+		 WHERE {a}.status = (SELECT id FROM submit_status WHERE description='approved')
+		   AND r.reviewed_at IS NULL
+		   AND r.creation_timestamp > COALESCE({a}.auto_hidden_at, '-infinity')
+		 GROUP BY {a}.id
+		 HAVING ($1 > 0 AND COUNT(*) > $1)
+		     OR ($2 > 0 AND COUNT(DISTINCT r.user_id) FILTER (WHERE r.creation_timestamp >= NOW() - $3::int * INTERVAL '1 hour') > $2)`),
+		threshold.REPORTS, threshold.DISTINCT_REPORTERS, threshold.WINDOW_HOURS,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (AutoHideCandidate, error) {
+		var c AutoHideCandidate
+		err := row.Scan(&c.ID, &c.OpenReports, &c.DistinctReporters)
+		return c, err
+	})
+}
+
+// HideContent riporta un elemento in attesa segnando l'occultamento
+// automatico; i termini vietati vanno ricontrollati
+func HideContent(db DBTX, kind *ContentKind, id int) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET status = (SELECT id FROM submit_status WHERE description='received'),
+		                 auto_hidden_at = NOW(), terms_checked_at = NULL
+		 WHERE id = $1`),
+		id,
+	))
+}
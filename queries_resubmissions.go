@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== RESUBMISSIONS ====================
+
+// UpsertRejectedFingerprint registra (o aggiorna, se rifiutato di nuovo)
+// l'impronta di un elemento rifiutato copiandone motivo e nota
+func UpsertRejectedFingerprint(db DBTX, kind *ContentKind, id int, hash, fingerprint int64, moderatorId *int, actorRole string) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`INSERT INTO rejected_fingerprints
+		     (kind, item_id, content_hash, simhash, rejection_reason, rejection_note, moderator_id, actor_role, rejected_at)
+		 SELECT '{kind}', id, $2, $3, rejection_reason, rejection_note, $4, $5, NOW() FROM {t} WHERE id = $1
+		 ON CONFLICT (kind, item_id) DO UPDATE
+		 SET content_hash = EXCLUDED.content_hash, simhash = EXCLUDED.simhash,
+		     rejection_reason = EXCLUDED.rejection_reason, rejection_note = EXCLUDED.rejection_note,
+		     moderator_id = EXCLUDED.moderator_id, actor_role = EXCLUDED.actor_role,
+		     rejected_at = EXCLUDED.rejected_at`),
+		id, hash, fingerprint, moderatorId, actorRole,
+	))
+}
+
+func DeleteRejectedFingerprint(db DBTX, kind *ContentKind, id int) error {
+	_, err := db.Exec(
+		context.Background(),
+		"DELETE FROM rejected_fingerprints WHERE kind = $1 AND item_id = $2",
+		kind.Name, id,
+	)
+	return err
+}
+
+// QueryUnrecordedRejections restituisce fino a limit elementi rifiutati
+// senza impronta registrata
+func QueryUnrecordedRejections(db *pgx.Conn, kind *ContentKind, limit int) ([]FingerprintCandidate, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, COALESCE({a}.content, '') FROM {t} {a}
+		 WHERE {a}.status = (SELECT id FROM submit_status WHERE description='rejected')
+		   AND NOT EXISTS (SELECT 1 FROM rejected_fingerprints rf WHERE rf.kind = '{kind}' AND rf.item_id = {a}.id)
+		 ORDER BY {a}.id DESC
+		 LIMIT $1`),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (FingerprintCandidate, error) {
+		var c FingerprintCandidate
+		err := row.Scan(&c.ID, &c.Content)
+		return c, err
+	})
+}
+
+// InsertPastRejection registra l'impronta di un elemento rifiutato in
+// passato; moderatore e data vengono dall'ultimo rifiuto nel log di audit,
+// se presente
+func InsertPastRejection(db DBTX, kind *ContentKind, id int, hash, fingerprint int64) error {
+	_, err := db.Exec(
+		context.Background(),
+		kind.sql(`INSERT INTO rejected_fingerprints
+		     (kind, item_id, content_hash, simhash, rejection_reason, rejection_note, moderator_id, actor_role, rejected_at)
+		 SELECT '{kind}', {a}.id, $2, $3, {a}.rejection_reason, {a}.rejection_note,
+		        m.id, a.actor_role, COALESCE(a.created_at, NOW())
+		 FROM {t} {a}
+		 LEFT JOIN LATERAL (
+		     SELECT actor_id, actor_role, created_at FROM moderation_audit
+		      WHERE target_type = '{kind}' AND target_id = {a}.id AND after_value->>'status' = 'rejected'
+		      ORDER BY created_at DESC, id DESC
+		      LIMIT 1
+		 ) a ON TRUE
+		 LEFT JOIN moderators m ON m.id = a.actor_id
+		 WHERE {a}.id = $1
+		 ON CONFLICT (kind, item_id) DO NOTHING`),
+		id, hash, fingerprint,
+	)
+	return err
+}
+
+// RejectedMatch è il contenuto rifiutato più vicino a un elemento
+type RejectedMatch struct {
+	ID       int
+	ItemID   int
+	Distance int
+	Reason   *string
+}
+
+// FindRejectedMatch cerca tra i contenuti rifiutati dello stesso tipo quello
+// con lo stesso testo normalizzato (distanza 0) o, in mancanza, quello con
+// l'impronta più vicina entro maxDistance. nil se non ce ne sono.
+func FindRejectedMatch(db DBTX, kind *ContentKind, id int, hash, fingerprint int64, maxDistance int) (*RejectedMatch, error) {
+	distance := fmt.Sprintf(SIMHASH_DISTANCE_EXPR, "rf.simhash", "$4")
+	var match RejectedMatch
+	err := db.QueryRow(
+		context.Background(),
+		`SELECT rf.id, rf.item_id, CASE WHEN rf.content_hash = $3 THEN 0 ELSE GREATEST(`+distance+`, 1) END as distance,
+		        rf.rejection_reason
+		 FROM rejected_fingerprints rf
+		 WHERE rf.kind = $1 AND rf.item_id <> $2
+		   AND (rf.content_hash = $3 OR (rf.simhash <> 0 AND $4 <> 0 AND `+distance+` <= $5))
+		 ORDER BY distance, rf.rejected_at DESC
+		 LIMIT 1`,
+		kind.Name, id, hash, fingerprint, maxDistance,
+	).Scan(&match.ID, &match.ItemID, &match.Distance, &match.Reason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+func SetResubmission(db DBTX, kind *ContentKind, id, fingerprintId, distance int) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql("UPDATE {t} SET resubmission_of = $2, resubmission_distance = $3 WHERE id = $1"),
+		id, fingerprintId, distance,
+	))
+}
+
+// QueryResubmissions restituisce, per gli elementi indicati, la decisione
+// presa sul contenuto rifiutato che ripropongono
+func QueryResubmissions(db DBTX, kind *ContentKind, ids []int) (map[int]Resubmission, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT {a}.id, rf.item_id, {a}.resubmission_distance, rf.rejected_at,
+		        rf.rejection_reason, rr.label, rf.rejection_note,
+		        rf.moderator_id, m.name, rf.actor_role
+		 FROM {t} {a}
+		 JOIN rejected_fingerprints rf ON rf.id = {a}.resubmission_of
+		 LEFT JOIN rejection_reasons rr ON rr.code = rf.rejection_reason
+		 LEFT JOIN moderators m ON m.id = rf.moderator_id
+		 WHERE {a}.id = ANY($1)`),
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resubmissions := map[int]Resubmission{}
+	for rows.Next() {
+		var itemId int
+		var r Resubmission
+		if err := rows.Scan(&itemId, &r.RejectedItemID, &r.Distance, &r.RejectedAt,
+			&r.Reason, &r.ReasonLabel, &r.Note, &r.ModeratorID, &r.ModeratorName, &r.ActorRole); err != nil {
+			return nil, err
+		}
+		r.Exact = r.Distance == 0
+		resubmissions[itemId] = r
+	}
+	return resubmissions, rows.Err()
+}
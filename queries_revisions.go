@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== REVISIONS ====================
+
+const REVISION_SELECT = `SELECT cr.id, cr.kind, cr.item_id, cr.revision, cr.content, cr.editor_id, m.name,
+        cr.reason, cr.reverted_from, cr.created_at
+ FROM content_revisions cr
+ LEFT JOIN moderators m ON cr.editor_id = m.id`
+
+func scanRevision(row pgx.Row) (ContentRevision, error) {
+	var r ContentRevision
+	err := row.Scan(&r.ID, &r.Kind, &r.ItemID, &r.Revision, &r.Content, &r.EditorID, &r.EditorName,
+		&r.Reason, &r.RevertedFrom, &r.CreatedAt)
+	return r, err
+}
+
+// QueryContentText legge testo e data di creazione bloccando la riga fino a
+// fine transazione. ErrNotFound se l'elemento non esiste.
+func QueryContentText(db DBTX, kind *ContentKind, id int) (string, time.Time, error) {
+	var content string
+	var created time.Time
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql("SELECT COALESCE(content, ''), creation_timestamp FROM {t} WHERE id = $1 FOR UPDATE"),
+		id,
+	).Scan(&content, &created)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", created, ErrNotFound
+	}
+	return content, created, err
+}
+
+// UpdateContentText sostituisce il testo di un elemento; i controlli su
+// termini vietati, dati personali, studenti nominati, quasi duplicati e reinvii vanno ripetuti sul
+// nuovo testo
+func UpdateContentText(db DBTX, kind *ContentKind, id int, content string) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET content = $2,
+		                 terms_checked_at = NULL,
+		                 pii_checked_at = NULL, pii_flagged_at = NULL, pii_flag_types = NULL,
+		                 mentions_checked_at = NULL, simhash = NULL, dup_group_id = NULL,
+		                 resubmission_of = NULL, resubmission_distance = NULL
+		 WHERE id = $1`),
+		id, content,
+	))
+}
+
+// QueryLatestRevision restituisce il numero dell'ultima revisione, 0 se il
+// testo non è mai stato modificato
+func QueryLatestRevision(db DBTX, kind *ContentKind, id int) (int, error) {
+	var latest int
+	err := db.QueryRow(
+		context.Background(),
+		"SELECT COALESCE(MAX(revision), 0) FROM content_revisions WHERE kind = $1 AND item_id = $2",
+		kind.Name, id,
+	).Scan(&latest)
+	return latest, err
+}
+
+// InsertRevision salva una revisione; createdAt nil significa adesso
+func InsertRevision(db DBTX, kind *ContentKind, id, revision int, content string, editorId *int, reason *string, revertedFrom *int, createdAt *time.Time) error {
+	_, err := db.Exec(
+		context.Background(),
+		`INSERT INTO content_revisions (kind, item_id, revision, content, editor_id, reason, reverted_from, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, NOW()))`,
+		kind.Name, id, revision, content, editorId, reason, revertedFrom, createdAt,
+	)
+	return err
+}
+
+// QueryRevisions restituisce tutte le revisioni di un elemento, dalla prima
+func QueryRevisions(db DBTX, kind *ContentKind, id int) ([]ContentRevision, error) {
+	rows, err := db.Query(
+		context.Background(),
+		REVISION_SELECT+" WHERE cr.kind = $1 AND cr.item_id = $2 ORDER BY cr.revision",
+		kind.Name, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []ContentRevision
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// QueryRevision restituisce una revisione; ErrNotFound se non esiste
+func QueryRevision(db DBTX, kind *ContentKind, id, revision int) (ContentRevision, error) {
+	r, err := scanRevision(db.QueryRow(
+		context.Background(),
+		REVISION_SELECT+" WHERE cr.kind = $1 AND cr.item_id = $2 AND cr.revision = $3",
+		kind.Name, id, revision,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r, ErrNotFound
+	}
+	return r, err
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== SANCTIONS ====================
+
+// Una sanzione è attiva finché non viene revocata o non scade
+const SANCTION_ACTIVE_EXPR = `(us.type <> 'warning' AND us.lifted_at IS NULL AND (us.expires_at IS NULL OR us.expires_at > NOW()))`
+
+const SANCTION_SELECT = `SELECT us.id, us.user_id, us.type, us.reason, us.moderator_id, m.name, us.actor_role,
+        us.created_at, us.expires_at, us.lifted_at, us.lifted_by, lm.name, us.lift_reason,
+        ` + SANCTION_ACTIVE_EXPR + `
+ FROM user_sanctions us
+ LEFT JOIN moderators m ON us.moderator_id = m.id
+ LEFT JOIN moderators lm ON us.lifted_by = lm.id`
+
+func scanSanction(row pgx.CollectableRow) (UserSanction, error) {
+	var s UserSanction
+	err := row.Scan(&s.ID, &s.UserID, &s.Type, &s.Reason, &s.ModeratorID, &s.ModeratorName, &s.ActorRole,
+		&s.CreatedAt, &s.ExpiresAt, &s.LiftedAt, &s.LiftedBy, &s.LiftedByName, &s.LiftReason,
+		&s.Active)
+	return s, err
+}
+
+// QueryUserExists restituisce ErrNotFound se l'utente non esiste
+func QueryUserExists(db DBTX, userId int) error {
+	var exists bool
+	err := db.QueryRow(context.Background(), "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", userId).Scan(&exists)
+	if err == nil && !exists {
+		return ErrNotFound
+	}
+	return err
+}
+
+// QueryUserSanctions restituisce lo stato attuale delle sanzioni di un
+// utente e lo storico, dalla più recente
+func QueryUserSanctions(db DBTX, userId int) (UserSanctions, error) {
+	var result UserSanctions
+	err := db.QueryRow(
+		context.Background(),
+		"SELECT suspended_until, banned_at, shadow_banned_at FROM users WHERE id = $1",
+		userId,
+	).Scan(&result.SuspendedUntil, &result.BannedAt, &result.ShadowBannedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return result, ErrNotFound
+	}
+	if err != nil {
+		return result, err
+	}
+
+	rows, err := db.Query(
+		context.Background(),
+		SANCTION_SELECT+"\n WHERE us.user_id = $1\n ORDER BY us.created_at DESC, us.id DESC",
+		userId,
+	)
+	if err != nil {
+		return result, err
+	}
+	result.Sanctions, err = pgx.CollectRows(rows, scanSanction)
+	return result, err
+}
+
+// QuerySanction legge una sanzione dell'utente; FOR UPDATE per revocarla
+func QuerySanction(db DBTX, userId, id int) (UserSanction, error) {
+	rows, err := db.Query(
+		context.Background(),
+		SANCTION_SELECT+"\n WHERE us.user_id = $1 AND us.id = $2\n FOR UPDATE OF us",
+		userId, id,
+	)
+	if err != nil {
+		return UserSanction{}, err
+	}
+	s, err := pgx.CollectExactlyOneRow(rows, scanSanction)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, ErrNotFound
+	}
+	return s, err
+}
+
+func InsertSanction(db DBTX, userId int, sanctionType, reason string, moderatorId *int, actorRole string, expiresAt *time.Time) (int, error) {
+	var id int
+	err := db.QueryRow(
+		context.Background(),
+		`INSERT INTO user_sanctions (user_id, type, reason, moderator_id, actor_role, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id`,
+		userId, sanctionType, reason, moderatorId, actorRole, expiresAt,
+	).Scan(&id)
+	return id, err
+}
+
+// LiftSanction revoca una sanzione ancora attiva; liftedBy è nil per le
+// revoche del server o dell'account statico
+func LiftSanction(db DBTX, id int, liftedBy *int, reason *string) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		"UPDATE user_sanctions SET lifted_at = NOW(), lifted_by = $2, lift_reason = $3 WHERE id = $1 AND lifted_at IS NULL",
+		id, liftedBy, reason,
+	))
+}
+
+// ExpireSanction chiude una sanzione scaduta alla sua scadenza
+func ExpireSanction(db DBTX, id int) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		`UPDATE user_sanctions SET lifted_at = expires_at, lift_reason = 'expired'
+		 WHERE id = $1 AND lifted_at IS NULL AND expires_at <= NOW()`,
+		id,
+	))
+}
+
+// QueryExpiredSanctions restituisce le sanzioni scadute ma non ancora chiuse
+func QueryExpiredSanctions(db *pgx.Conn) ([]UserSanction, error) {
+	rows, err := db.Query(
+		context.Background(),
+		SANCTION_SELECT+"\n WHERE us.lifted_at IS NULL AND us.expires_at <= NOW()\n ORDER BY us.expires_at",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanSanction)
+}
+
+// RefreshUserSanctions ricalcola le colonne di users dalle sanzioni attive e
+// trattiene o rilascia gli elementi in attesa secondo lo shadow-ban.
+// Restituisce il nuovo stato.
+func RefreshUserSanctions(db DBTX, userId int) (UserSanctions, error) {
+	var state UserSanctions
+	err := db.QueryRow(
+		context.Background(),
+		`UPDATE users SET
+		     suspended_until = (SELECT MAX(us.expires_at) FROM user_sanctions us
+		                         WHERE us.user_id = $1 AND us.type = 'suspension' AND `+SANCTION_ACTIVE_EXPR+`),
+		     banned_at = (SELECT MIN(us.created_at) FROM user_sanctions us
+		                   WHERE us.user_id = $1 AND us.type = 'ban' AND `+SANCTION_ACTIVE_EXPR+`),
+		     shadow_banned_at = (SELECT MIN(us.created_at) FROM user_sanctions us
+		                          WHERE us.user_id = $1 AND us.type = 'shadow_ban' AND `+SANCTION_ACTIVE_EXPR+`)
+		 WHERE id = $1
+		 RETURNING suspended_until, banned_at, shadow_banned_at`,
+		userId,
+	).Scan(&state.SuspendedUntil, &state.BannedAt, &state.ShadowBannedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return state, ErrNotFound
+	}
+	if err != nil {
+		return state, err
+	}
+
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		query := `UPDATE {t} SET held_at = NULL
+		 WHERE creator = $1 AND held_at IS NOT NULL
+		   AND status = (SELECT id FROM submit_status WHERE description='received')`
+		if state.ShadowBannedAt != nil {
+			query = `UPDATE {t} SET held_at = NOW()
+			 WHERE creator = $1 AND held_at IS NULL
+			   AND status = (SELECT id FROM submit_status WHERE description='received')`
+		}
+		if _, err := db.Exec(context.Background(), kind.sql(query), userId); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== PUBBLICAZIONE PROGRAMMATA ====================
+
+// QueryContentSchedule legge la data di pubblicazione programmata; nil se
+// l'elemento non è programmato. Va chiamata dopo QueryContentStatus, che
+// blocca la riga.
+func QueryContentSchedule(db DBTX, kind *ContentKind, id int) (*time.Time, error) {
+	var publishAt *time.Time
+	err := db.QueryRow(
+		context.Background(),
+		kind.sql("SELECT publish_at FROM {t} WHERE id = $1"),
+		id,
+	).Scan(&publishAt)
+	return publishAt, err
+}
+
+// SetContentSchedule programma la pubblicazione; con publishAt nil la annulla
+// e l'elemento torna nella coda in attesa
+func SetContentSchedule(db DBTX, kind *ContentKind, id int, publishAt *time.Time, moderatorId *int) error {
+	if publishAt == nil {
+		moderatorId = nil
+	}
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql("UPDATE {t} SET publish_at = $2, scheduled_by = $3 WHERE id = $1"),
+		id, publishAt, moderatorId,
+	))
+}
+
+// QueryDueScheduled restituisce gli elementi programmati la cui data di
+// pubblicazione è passata, dal più vecchio
+func QueryDueScheduled(db *pgx.Conn, kind *ContentKind) ([]int, error) {
+	rows, err := db.Query(
+		context.Background(),
+		kind.sql(`SELECT id FROM {t}
+		 WHERE publish_at <= NOW()
+		   AND status = (SELECT id FROM submit_status WHERE description='received')
+		 ORDER BY publish_at, id`),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// PublishScheduledContent approva un elemento programmato e scaduto.
+// ErrNotFound se nel frattempo è stato deciso, riprogrammato o annullato.
+func PublishScheduledContent(db DBTX, kind *ContentKind, id int) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		kind.sql(`UPDATE {t} SET status = (SELECT id FROM submit_status WHERE description='approved'),
+		                 approval_timestamp = NOW(),
+		                 rejection_reason = NULL, rejection_note = NULL,
+		                 publish_at = NULL, scheduled_by = NULL
+		 WHERE id = $1 AND publish_at <= NOW()
+		   AND status = (SELECT id FROM submit_status WHERE description='received')`),
+		id,
+	))
+}
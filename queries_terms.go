@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== BANNED TERMS ====================
+
+const BANNED_TERM_SELECT = `SELECT t.id, t.pattern, t.is_regex, t.severity, t.action, t.rejection_reason,
+        t.active, t.created_at, m.name
+ FROM banned_terms t
+ LEFT JOIN moderators m ON t.created_by = m.id`
+
+func scanBannedTerm(row pgx.CollectableRow) (BannedTerm, error) {
+	var t BannedTerm
+	err := row.Scan(
+		&t.ID, &t.Pattern, &t.IsRegex, &t.Severity, &t.Action, &t.RejectionReason,
+		&t.Active, &t.CreatedAt, &t.CreatedByName,
+	)
+	return t, err
+}
+
+func QueryBannedTerms(db *pgx.Conn) ([]BannedTerm, error) {
+	rows, err := db.Query(context.Background(), BANNED_TERM_SELECT+" ORDER BY t.active DESC, t.pattern")
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanBannedTerm)
+}
+
+func QueryActiveBannedTerms(db DBTX) ([]BannedTerm, error) {
+	rows, err := db.Query(context.Background(), BANNED_TERM_SELECT+" WHERE t.active ORDER BY t.id")
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanBannedTerm)
+}
+
+func InsertBannedTerm(db *pgx.Conn, req BannedTermRequest, createdBy *int) error {
+	_, err := db.Exec(
+		context.Background(),
+		`INSERT INTO banned_terms (pattern, is_regex, severity, action, rejection_reason, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		req.Pattern, req.IsRegex, req.Severity, req.Action, req.RejectionReason, createdBy,
+	)
+	return err
+}
+
+func UpdateBannedTerm(db *pgx.Conn, id int, req BannedTermRequest, active bool) error {
+	return checkAffected(db.Exec(
+		context.Background(),
+		`UPDATE banned_terms SET pattern = $2, is_regex = $3, severity = $4, action = $5,
+		                        rejection_reason = $6, active = $7
+		 WHERE id = $1`,
+		id, req.Pattern, req.IsRegex, req.Severity, req.Action, req.RejectionReason, active,
+	))
+}
+
+func DeleteBannedTerm(db *pgx.Conn, id int) error {
+	return checkAffected(db.Exec(context.Background(), "DELETE FROM banned_terms WHERE id = $1", id))
+}
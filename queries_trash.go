@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ==================== TRASH ====================
+
+// TrashContent sposta l'elemento nel cestino insieme a like e segnalazioni,
+// poi lo rimuove dalle tabelle attive. Restituisce ErrNotFound se non esiste.
+func TrashContent(db DBTX, kind *ContentKind, id int, deletedBy *int) (DeleteResult, error) {
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		return DeleteResult{}, err
+	}
+	defer tx.Rollback(context.Background())
+
+	err = checkAffected(tx.Exec(
+		context.Background(),
+		kind.sql(`INSERT INTO content_trash (kind, item_id, item, likes, reports, deleted_by)
+		 SELECT $1, {a}.id, to_jsonb({a}),
+		        COALESCE((SELECT jsonb_agg(to_jsonb(l)) FROM {likes} l WHERE l.{fk} = {a}.id), '[]'),
+		        COALESCE((SELECT jsonb_agg(to_jsonb(r)) FROM {reports} r WHERE r.{fk} = {a}.id), '[]'),
+		        $3
+		 FROM {t} {a}
+		 WHERE {a}.id = $2`),
+		kind.Name, id, deletedBy,
+	))
+	if err != nil {
+		return DeleteResult{}, err
+	}
+
+	result, err := DeleteContentById(tx, kind, id)
+	if err != nil {
+		return DeleteResult{}, err
+	}
+
+	return result, tx.Commit(context.Background())
+}
+
+// RestoreContent reinserisce l'elemento con i suoi like e segnalazioni
+// originali (stessi ID) e lo toglie dal cestino. ErrNotFound se non è nel cestino.
+func RestoreContent(db DBTX, kind *ContentKind, id int) (RestoreResult, error) {
+	var result RestoreResult
+
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(context.Background())
+
+	var item, likes, reports []byte
+	err = tx.QueryRow(
+		context.Background(),
+		"DELETE FROM content_trash WHERE kind = $1 AND item_id = $2 RETURNING item, likes, reports",
+		kind.Name, id,
+	).Scan(&item, &likes, &reports)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return result, ErrNotFound
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if _, err := tx.Exec(
+		context.Background(),
+		// Il controllo dei termini va ripetuto: l'elenco può essere cambiato
+		kind.sql("INSERT INTO {t} SELECT * FROM jsonb_populate_record(NULL::{t}, $1::jsonb - 'terms_checked_at')"),
+		item,
+	); err != nil {
+		return result, err
+	}
+
+	tag, err := tx.Exec(
+		context.Background(),
+		kind.sql("INSERT INTO {likes} SELECT * FROM jsonb_populate_recordset(NULL::{likes}, $1)"),
+		likes,
+	)
+	if err != nil {
+		return result, err
+	}
+	result.LikesRestored = tag.RowsAffected()
+
+	tag, err = tx.Exec(
+		context.Background(),
+		kind.sql("INSERT INTO {reports} SELECT * FROM jsonb_populate_recordset(NULL::{reports}, $1)"),
+		reports,
+	)
+	if err != nil {
+		return result, err
+	}
+	result.ReportsRestored = tag.RowsAffected()
+
+	return result, tx.Commit(context.Background())
+}
+
+func CountTrash(db *pgx.Conn, kind *ContentKind) (int, error) {
+	var total int
+	err := db.QueryRow(
+		context.Background(),
+		"SELECT COUNT(*) FROM content_trash WHERE kind = $1",
+		kind.Name,
+	).Scan(&total)
+	return total, err
+}
+
+// QueryTrash elenca gli elementi nel cestino, dal più recente
+func QueryTrash(db *pgx.Conn, kind *ContentKind, limit, offset int) (pgx.Rows, error) {
+	return db.Query(
+		context.Background(),
+		`SELECT t.item_id, COALESCE(t.item->>'content', ''), (t.item->>'creator')::int,
+		        COALESCE(u.first_name, 'N/A'), COALESCE(u.last_name, ''),
+		        (t.item->>'creation_timestamp')::timestamp,
+		        jsonb_array_length(t.likes), jsonb_array_length(t.reports),
+		        t.deleted_at, t.deleted_by, m.name
+		 FROM content_trash t
+		 LEFT JOIN users u ON u.id = (t.item->>'creator')::int
+		 LEFT JOIN moderators m ON t.deleted_by = m.id
+		 WHERE t.kind = $1
+		 ORDER BY t.deleted_at DESC, t.id DESC
+		 LIMIT $2 OFFSET $3`,
+		kind.Name, limit, offset,
+	)
+}
+
+// PurgeTrash elimina definitivamente gli elementi cestinati da più di
+// retention, insieme a revisioni e menzioni, e li restituisce per l'audit.
+// Il limite è calcolato dal database, nello stesso fuso di deleted_at.
+func PurgeTrash(db DBTX, retention time.Duration) ([]PurgedItem, error) {
+	rows, err := db.Query(
+		context.Background(),
+		`WITH purged AS (
+		     DELETE FROM content_trash WHERE deleted_at < NOW() - $1::int * INTERVAL '1 second'
+		     RETURNING kind, item_id, COALESCE(item->>'content', '') AS content, deleted_at
+		 ), revisions AS (
+		     DELETE FROM content_revisions cr USING purged p
+		      WHERE cr.kind = p.kind AND cr.item_id = p.item_id
+		 ), mentions AS (
+		     DELETE FROM content_mentions cm USING purged p
+		      WHERE cm.kind = p.kind AND cm.item_id = p.item_id
+		 )
+		 SELECT kind, item_id, content, deleted_at FROM purged`,
+		int(retention.Seconds()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purged []PurgedItem
+	for rows.Next() {
+		var p PurgedItem
+		if err := rows.Scan(&p.Kind, &p.ItemID, &p.Content, &p.DeletedAt); err != nil {
+			return nil, err
+		}
+		purged = append(purged, p)
+	}
+	return purged, rows.Err()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	REPLICA_CONNECT_TIMEOUT = 3 * time.Second
+	// Dopo un errore la replica viene saltata per questo intervallo,
+	// così le richieste non pagano ogni volta il timeout di connessione.
+	REPLICA_RETRY_AFTER = 30 * time.Second
+	// Ritardo massimo di default se replica_max_lag_seconds non è impostato
+	DEFAULT_REPLICA_MAX_LAG = 30 * time.Second
+)
+
+var replicaState struct {
+	sync.Mutex
+	downUntil time.Time
+}
+
+func replicaConnString() string {
+	port := CONF.REPLICA_PORT
+	if port == 0 {
+		port = CONF.DBASE_PORT
+	}
+	name, user, passwd := CONF.REPLICA_NAME, CONF.REPLICA_USER, CONF.REPLICA_PASSWD
+	if name == "" {
+		name = CONF.DBASE_NAME
+	}
+	if user == "" {
+		user = CONF.DBASE_USER
+		passwd = CONF.DBASE_PASSWD
+	}
+	// url.URL esegue l'escape di utente e password (es. @, /, :, #)
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, passwd),
+		Host:     net.JoinHostPort(CONF.REPLICA_HOST, strconv.Itoa(port)),
+		Path:     "/" + name,
+		RawQuery: "connect_timeout=" + strconv.Itoa(int(REPLICA_CONNECT_TIMEOUT.Seconds())),
+	}
+	return dsn.String()
+}
+
+func replicaMaxLag() time.Duration {
+	if CONF.REPLICA_MAX_LAG_SECONDS > 0 {
+		return time.Duration(CONF.REPLICA_MAX_LAG_SECONDS) * time.Second
+	}
+	return DEFAULT_REPLICA_MAX_LAG
+}
+
+func markReplicaDown(reason string) {
+	replicaState.Lock()
+	replicaState.downUntil = time.Now().Add(REPLICA_RETRY_AFTER)
+	replicaState.Unlock()
+	log.Printf("replica unavailable, falling back to primary: %s", reason)
+}
+
+// replicaConnection apre una connessione alla replica e ne verifica il ritardo.
+// Restituisce nil se la replica non è configurata, non raggiungibile o troppo indietro.
+func replicaConnection() *pgx.Conn {
+	if CONF.REPLICA_HOST == "" {
+		return nil
+	}
+
+	replicaState.Lock()
+	skip := time.Now().Before(replicaState.downUntil)
+	replicaState.Unlock()
+	if skip {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), REPLICA_CONNECT_TIMEOUT)
+	defer cancel()
+
+	db, err := pgx.Connect(ctx, replicaConnString())
+	if err != nil {
+		markReplicaDown(err.Error())
+		return nil
+	}
+
+	// Se la replica ha ricevuto e applicato tutto il WAL il ritardo è zero anche
+	// quando il primario è inattivo; fuori da recovery (es. promossa) vale zero.
+	var lagSeconds float64
+	err = db.QueryRow(
+		ctx,
+		`SELECT CASE
+			WHEN NOT pg_is_in_recovery() THEN 0
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp()), 0)
+		 END`,
+	).Scan(&lagSeconds)
+	if err != nil {
+		db.Close(context.Background())
+		markReplicaDown(err.Error())
+		return nil
+	}
+
+	if lag := time.Duration(lagSeconds * float64(time.Second)); lag > replicaMaxLag() {
+		db.Close(context.Background())
+		markReplicaDown(fmt.Sprintf("lag %s exceeds %s", lag.Round(time.Second), replicaMaxLag()))
+		return nil
+	}
+
+	return db
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Elementi rifiutati prima dell'introduzione delle impronte registrati a ogni giro
+const REJECTION_BACKFILL_BATCH = 500
+
+// Tipi di contenuto il cui storico dei rifiuti è già stato registrato da
+// questo processo; usata solo dal goroutine delle impronte
+var rejectionsBackfilled = map[string]bool{}
+
+// recordRejection registra l'impronta di un elemento appena rifiutato con
+// la decisione presa, per riconoscerne i reinvii
+func recordRejection(tx pgx.Tx, entry AuditEntry, kind *ContentKind, id int) error {
+	content, err := QueryContentFingerprint(tx, kind, id)
+	if err != nil {
+		return err
+	}
+	return UpsertRejectedFingerprint(tx, kind, id, contentHash(content), simhash(content), entry.ActorID, entry.ActorRole)
+}
+
+// recordPastRejections registra le impronte degli elementi rifiutati che non
+// ne hanno ancora una, ricavando il moderatore dal log di audit. I nuovi
+// rifiuti sono registrati da recordRejection: finito lo storico la ricerca
+// non si ripete più fino al prossimo avvio.
+func recordPastRejections(db *pgx.Conn, kind *ContentKind) error {
+	if rejectionsBackfilled[kind.Name] {
+		return nil
+	}
+	pending, err := QueryUnrecordedRejections(db, kind, REJECTION_BACKFILL_BATCH)
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if err := InsertPastRejection(db, kind, p.ID, contentHash(p.Content), simhash(p.Content)); err != nil {
+			return err
+		}
+	}
+	if len(pending) < REJECTION_BACKFILL_BATCH {
+		rejectionsBackfilled[kind.Name] = true
+	}
+	return nil
+}
+
+// checkResubmission confronta un elemento in attesa con i contenuti già
+// rifiutati dello stesso tipo. I reinvii identici (stesso testo normalizzato)
+// vengono rifiutati automaticamente se auto_reject_resubmissions è attivo,
+// con lo stesso motivo del rifiuto originale.
+// Restituisce se l'elemento è un reinvio e se è stato rifiutato.
+func checkResubmission(db *pgx.Conn, kind *ContentKind, id int, content string, fingerprint int64) (bool, bool, error) {
+	hash := contentHash(content)
+	if hash == 0 {
+		return false, false, nil
+	}
+	match, err := FindRejectedMatch(db, kind, id, hash, fingerprint, SIMHASH_MAX_DISTANCE)
+	if err != nil || match == nil {
+		return false, false, err
+	}
+
+	if match.Distance == 0 && CONF.AUTO_REJECT_RESUBMISSIONS {
+		entry := AuditEntry{
+			Action:     "auto_reject_resubmission",
+			TargetType: kind.Name,
+			TargetID:   id,
+			ActorRole:  ACTOR_SYSTEM,
+		}
+		note := "Reinvio di un contenuto già rifiutato (#" + strconv.Itoa(match.ItemID) + ")"
+		err := auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+			if err := checkStillPending(tx, kind, id); err != nil {
+				return err
+			}
+			if err := SetResubmission(tx, kind, id, match.ID, match.Distance); err != nil {
+				return err
+			}
+			return RejectContent(tx, kind, id, match.Reason, &note)
+		})
+		if errors.Is(err, errAlreadyHandled) {
+			return false, false, nil
+		}
+		return err == nil, err == nil, err
+	}
+
+	entry := AuditEntry{
+		Action:     "resubmission_flag",
+		TargetType: kind.Name,
+		TargetID:   id,
+		ActorRole:  ACTOR_SYSTEM,
+	}
+	err = runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		if err := SetResubmission(tx, kind, id, match.ID, match.Distance); err != nil {
+			return nil, nil, err
+		}
+		return nil, map[string]any{"rejected_item": match.ItemID, "distance": match.Distance}, nil
+	})
+	return err == nil, false, err
+}
+
+// annotateResubmissions allega agli elementi che ripropongono contenuti già
+// rifiutati la decisione originale
+func annotateResubmissions(db DBTX, kind *ContentKind, items []ContentItem) {
+	var ids []int
+	for _, item := range items {
+		if item.ResubmissionDistance != nil {
+			ids = append(ids, item.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	resubmissions, err := QueryResubmissions(db, kind, ids)
+	if err != nil {
+		println("Resubmissions error ("+kind.Name+"):", err.Error())
+		return
+	}
+	for i := range items {
+		if r, ok := resubmissions[items[i].ID]; ok {
+			items[i].Resubmission = &r
+		}
+	}
+}
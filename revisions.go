@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Oltre questa dimensione della tabella LCS il diff mostra solo testo tolto e aggiunto
+const MAX_DIFF_CELLS = 4_000_000
+
+var errContentUnchanged = errors.New("content unchanged")
+
+// auditedEditContent sostituisce il testo di un elemento salvando la nuova
+// revisione. Alla prima modifica il testo originale diventa la revisione 1.
+// revertedFrom indica la revisione ripristinata, nil per una modifica.
+// Restituisce il numero della nuova revisione.
+func auditedEditContent(db DBTX, entry AuditEntry, kind *ContentKind, id int, content string, reason *string, revertedFrom *int) (int, error) {
+	var revision int
+	err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		current, created, err := QueryContentText(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkClaim(tx, kind, id, entry.ActorID); err != nil {
+			return nil, nil, err
+		}
+		if current == content {
+			return nil, nil, errContentUnchanged
+		}
+
+		latest, err := QueryLatestRevision(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if latest == 0 {
+			// Il testo dell'autore, con la data di pubblicazione
+			if err := InsertRevision(tx, kind, id, 1, current, nil, nil, nil, &created); err != nil {
+				return nil, nil, err
+			}
+			latest = 1
+		}
+
+		if err := UpdateContentText(tx, kind, id, content); err != nil {
+			return nil, nil, err
+		}
+		revision = latest + 1
+		if err := InsertRevision(tx, kind, id, revision, content, entry.ActorID, reason, revertedFrom, nil); err != nil {
+			return nil, nil, err
+		}
+
+		after := map[string]any{"content": content, "revision": revision, "reason": reason}
+		if revertedFrom != nil {
+			after["reverted_from"] = *revertedFrom
+		}
+		return map[string]any{"content": current, "revision": latest}, after, nil
+	})
+	return revision, err
+}
+
+// diffTokens divide il testo in parole, spazi e singoli segni di punteggiatura,
+// così il diff resta leggibile senza spezzare le parole
+func diffTokens(text string) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch {
+		case unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]):
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+		case unicode.IsSpace(runes[i]):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+// diffText confronta due testi parola per parola (sottosequenza comune più lunga)
+func diffText(from, to string) []DiffOp {
+	a, b := diffTokens(from), diffTokens(to)
+
+	// Prefisso e suffisso comuni non entrano nella tabella
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []DiffOp
+	add := func(op string, tokens ...string) {
+		text := strings.Join(tokens, "")
+		if text == "" {
+			return
+		}
+		if len(ops) > 0 && ops[len(ops)-1].Op == op {
+			ops[len(ops)-1].Text += text
+			return
+		}
+		ops = append(ops, DiffOp{Op: op, Text: text})
+	}
+
+	add("equal", a[:prefix]...)
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(midA), len(midB)
+
+	if (n+1)*(m+1) > MAX_DIFF_CELLS {
+		add("delete", midA...)
+		add("insert", midB...)
+	} else {
+		// lcs[i][j] = lunghezza della sottosequenza comune di midA[i:] e midB[j:]
+		lcs := make([][]int32, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < n && j < m {
+			switch {
+			case midA[i] == midB[j]:
+				add("equal", midA[i])
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				add("delete", midA[i])
+				i++
+			default:
+				add("insert", midB[j])
+				j++
+			}
+		}
+		add("delete", midA[i:]...)
+		add("insert", midB[j:]...)
+	}
+
+	add("equal", a[len(a)-suffix:]...)
+	if ops == nil {
+		ops = []DiffOp{}
+	}
+	return ops
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"ciao", []string{"ciao"}},
+		{"ciao,  mondo!", []string{"ciao", ",", "  ", "mondo", "!"}},
+		{"perché 3 volte", []string{"perché", " ", "3", " ", "volte"}},
+		{"...", []string{".", ".", "."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := diffTokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffTokens(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []DiffOp
+	}{
+		{"uguali", "ciao mondo", "ciao mondo", []DiffOp{{"equal", "ciao mondo"}}},
+		{"entrambi vuoti", "", "", []DiffOp{}},
+		{"da vuoto", "", "ciao", []DiffOp{{"insert", "ciao"}}},
+		{"a vuoto", "ciao", "", []DiffOp{{"delete", "ciao"}}},
+		{"parola sostituita", "ciao bel mondo", "ciao brutto mondo", []DiffOp{
+			{"equal", "ciao "}, {"delete", "bel"}, {"insert", "brutto"}, {"equal", " mondo"},
+		}},
+		{"parola aggiunta", "ciao mondo", "ciao a tutto il mondo", []DiffOp{
+			{"equal", "ciao "}, {"insert", "a tutto il "}, {"equal", "mondo"},
+		}},
+		{"parola tolta", "sei proprio uno stupido", "sei uno stupido", []DiffOp{
+			{"equal", "sei "}, {"delete", "proprio "}, {"equal", "uno stupido"},
+		}},
+		{"punteggiatura", "ciao.", "ciao!", []DiffOp{{"equal", "ciao"}, {"delete", "."}, {"insert", "!"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffText(tt.from, tt.to)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffText(%q, %q) = %+v, want %+v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffTextReconstructs(t *testing.T) {
+	// Le operazioni devono ricostruire entrambi i testi
+	pairs := [][2]string{
+		{"il gatto è sul tavolo", "il cane è sotto il tavolo"},
+		{"a b c d e f", "f e d c b a"},
+		{"uno, due, tre", "uno due tre quattro"},
+	}
+	for _, p := range pairs {
+		var from, to strings.Builder
+		for _, op := range diffText(p[0], p[1]) {
+			if op.Op != "insert" {
+				from.WriteString(op.Text)
+			}
+			if op.Op != "delete" {
+				to.WriteString(op.Text)
+			}
+		}
+		if from.String() != p[0] || to.String() != p[1] {
+			t.Errorf("diffText(%q, %q) ricostruisce %q e %q", p[0], p[1], from.String(), to.String())
+		}
+	}
+}
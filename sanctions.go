@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	SANCTION_EXPIRY_INTERVAL = time.Minute
+
+	MAX_SANCTION_REASON = 500
+	MAX_SANCTION_HOURS  = 24 * 365
+)
+
+var validSanctionTypes = map[string]bool{"warning": true, "suspension": true, "ban": true, "shadow_ban": true}
+
+// Tipi che ammettono (suspension: richiedono) una scadenza
+var expiringSanctionTypes = map[string]bool{"suspension": true, "shadow_ban": true}
+
+var errSanctionNotActive = errors.New("sanction is not active")
+
+// auditedSanction registra una nuova sanzione e aggiorna lo stato dell'utente
+func auditedSanction(db DBTX, entry AuditEntry, userId int, req SanctionRequest, expiresAt *time.Time) (UserSanction, error) {
+	var sanction UserSanction
+	err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		before, err := RefreshUserSanctions(tx, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		id, err := InsertSanction(tx, userId, req.Type, req.Reason, entry.ActorID, entry.ActorRole, expiresAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := RefreshUserSanctions(tx, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sanction, err = QuerySanction(tx, userId, id); err != nil {
+			return nil, nil, err
+		}
+		return sanctionState(before), map[string]any{
+			"sanction_id": id,
+			"type":        req.Type,
+			"reason":      req.Reason,
+			"expires_at":  expiresAt,
+			"state":       sanctionState(after),
+		}, nil
+	})
+	return sanction, err
+}
+
+// auditedLiftSanction revoca una sanzione attiva (o la chiude se scaduta,
+// con liftedBy nil e reason "expired") e aggiorna lo stato dell'utente
+func auditedLiftSanction(db DBTX, entry AuditEntry, userId, id int, reason *string) (UserSanction, error) {
+	var sanction UserSanction
+	err := runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		current, err := QuerySanction(tx, userId, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if current.LiftedAt != nil || current.Type == "warning" {
+			return nil, nil, errSanctionNotActive
+		}
+		before, err := RefreshUserSanctions(tx, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if entry.ActorRole == ACTOR_SYSTEM {
+			err = ExpireSanction(tx, id)
+		} else {
+			err = LiftSanction(tx, id, entry.ActorID, reason)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := RefreshUserSanctions(tx, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sanction, err = QuerySanction(tx, userId, id); err != nil {
+			return nil, nil, err
+		}
+		return sanctionState(before), map[string]any{
+			"sanction_id": id,
+			"type":        current.Type,
+			"lift_reason": sanction.LiftReason,
+			"state":       sanctionState(after),
+		}, nil
+	})
+	return sanction, err
+}
+
+// sanctionState è la parte di UserSanctions salvata nell'audit
+func sanctionState(s UserSanctions) map[string]any {
+	return map[string]any{
+		"suspended_until":  s.SuspendedUntil,
+		"banned_at":        s.BannedAt,
+		"shadow_banned_at": s.ShadowBannedAt,
+	}
+}
+
+// rejectPendingFromUser rifiuta gli elementi in attesa dell'utente,
+// trattenuti compresi e fino a MAX_BULK_SIZE per tipo, ciascuno con la
+// propria voce di audit come un rifiuto massivo. Gli elementi riservati da altri moderatori restano in
+// coda e vengono riportati tra gli errori.
+func rejectPendingFromUser(ctx *gin.Context, db *pgx.Conn, userId int, reason, note *string) (int, []BulkItemResult, error) {
+	rejected := 0
+	failures := []BulkItemResult{}
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		// La coda in attesa esclude i programmati: l'approvazione di un
+		// moderatore resta valida anche se l'autore viene bandito
+		ids, err := QueryContentIds(db, kind, ContentListParams{
+			Queue:     QUEUE_PENDING,
+			CreatorID: &userId,
+			Held:      HELD_INCLUDE,
+		}, MAX_BULK_SIZE)
+		if err != nil {
+			return rejected, failures, err
+		}
+		op := bulkOperation(ctx, kind, BulkRequest{Action: "reject"}, reason, note)
+		for _, id := range ids {
+			if err := op(db, id); err != nil {
+				failures = append(failures, bulkItemError(kind, id, err))
+				continue
+			}
+			rejected++
+		}
+	}
+	return rejected, failures, nil
+}
+
+// liftExpiredSanctions chiude le sanzioni scadute con una voce di audit di
+// sistema; l'utente torna attivo se non ne ha altre
+func liftExpiredSanctions(db *pgx.Conn) (int, error) {
+	expired, err := QueryExpiredSanctions(db)
+	if err != nil {
+		return 0, err
+	}
+	lifted := 0
+	for _, s := range expired {
+		entry := AuditEntry{
+			Action:     "lift_sanction",
+			TargetType: "user",
+			TargetID:   s.UserID,
+			ActorRole:  ACTOR_SYSTEM,
+		}
+		if _, err := auditedLiftSanction(db, entry, s.UserID, s.ID, nil); err != nil {
+			// Revocata nel frattempo da un moderatore
+			if errors.Is(err, errSanctionNotActive) || errors.Is(err, ErrNotFound) {
+				continue
+			}
+			println("Sanction expiry error:", err.Error())
+			continue
+		}
+		lifted++
+	}
+	return lifted, nil
+}
+
+// startSanctionExpirer avvia in background la chiusura delle sanzioni scadute
+func startSanctionExpirer() {
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("Sanctions: cannot connect to database:", err.Error())
+			} else {
+				count, err := liftExpiredSanctions(db)
+				if err != nil {
+					println("Sanctions error:", err.Error())
+				} else if count > 0 {
+					println("Sanctions: lifted", count, "expired sanctions")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(SANCTION_EXPIRY_INTERVAL)
+		}
+	}()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	PUBLISH_SCHEDULER_INTERVAL = time.Minute
+
+	// Anticipo massimo di una pubblicazione programmata
+	MAX_SCHEDULE_DAYS = 365
+)
+
+var (
+	errNotPending   = errors.New("content is not pending")
+	errNotScheduled = errors.New("content is not scheduled")
+)
+
+// auditedSchedule programma (publishAt valorizzato) o annulla (nil) la
+// pubblicazione di un elemento in attesa. Con requireScheduled l'elemento
+// deve essere già programmato (riprogrammazione e annullamento).
+// Programmare è una decisione: chiude l'eventuale riserva.
+func auditedSchedule(db DBTX, entry AuditEntry, kind *ContentKind, id int, publishAt *time.Time, requireScheduled bool) error {
+	return runAudited(db, entry, func(tx pgx.Tx) (any, any, error) {
+		status, err := QueryContentStatus(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if status != "received" {
+			return nil, nil, errNotPending
+		}
+		before, err := QueryContentSchedule(tx, kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if requireScheduled && before == nil {
+			return nil, nil, errNotScheduled
+		}
+		if err := checkClaim(tx, kind, id, entry.ActorID); err != nil {
+			return nil, nil, err
+		}
+		if err := SetContentSchedule(tx, kind, id, publishAt, entry.ActorID); err != nil {
+			return nil, nil, err
+		}
+		if err := DeleteClaim(tx, kind, id); err != nil {
+			return nil, nil, err
+		}
+		return map[string]any{"status": status, "publish_at": before},
+			map[string]any{"status": status, "publish_at": publishAt}, nil
+	})
+}
+
+// validatePublishAt controlla che la data sia nel futuro e non oltre
+// MAX_SCHEDULE_DAYS; risponde 400 altrimenti
+func validatePublishAt(ctx *gin.Context, publishAt time.Time) bool {
+	now := time.Now()
+	if !publishAt.After(now) || publishAt.After(now.AddDate(0, 0, MAX_SCHEDULE_DAYS)) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{
+			Status: "error",
+			Error:  "invalid_publish_at",
+			Msg:    "publish_at must be in the future and within 365 days",
+		})
+		return false
+	}
+	return true
+}
+
+// respondScheduleError gestisce gli errori specifici della programmazione;
+// restituisce false se err va gestito dal chiamante
+func respondScheduleError(ctx *gin.Context, kind *ContentKind, err error) bool {
+	switch {
+	case errors.Is(err, errNotPending):
+		ctx.JSON(http.StatusConflict, ErrorResponse{
+			Status: "error",
+			Error:  "not_pending",
+			Msg:    "Only pending " + kind.Name + " items can be scheduled",
+		})
+	case errors.Is(err, errNotScheduled):
+		ctx.JSON(http.StatusConflict, ErrorResponse{
+			Status: "error",
+			Error:  "not_scheduled",
+			Msg:    kind.Label + " is not scheduled for publishing",
+		})
+	default:
+		return respondClaimConflict(ctx, kind, err)
+	}
+	return true
+}
+
+// publishDueContent approva gli elementi la cui pubblicazione programmata è
+// arrivata, registrando ogni approvazione come azione di sistema
+func publishDueContent(db *pgx.Conn) (int, error) {
+	published := 0
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		ids, err := QueryDueScheduled(db, kind)
+		if err != nil {
+			return published, err
+		}
+		for _, id := range ids {
+			entry := AuditEntry{
+				Action:     "scheduled_publish",
+				TargetType: kind.Name,
+				TargetID:   id,
+				ActorRole:  ACTOR_SYSTEM,
+			}
+			err := auditedStatusChange(db, entry, kind, id, func(tx pgx.Tx) error {
+				return PublishScheduledContent(tx, kind, id)
+			})
+			if err != nil {
+				// Deciso, riprogrammato o eliminato nel frattempo
+				if errors.Is(err, ErrNotFound) || errors.Is(err, pgx.ErrNoRows) {
+					continue
+				}
+				println("Scheduled publish error ("+kind.Name+"):", err.Error())
+				continue
+			}
+			published++
+		}
+	}
+	return published, nil
+}
+
+// startPublishScheduler avvia in background la pubblicazione degli elementi programmati
+func startPublishScheduler() {
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("Scheduler: cannot connect to database:", err.Error())
+			} else {
+				count, err := publishDueContent(db)
+				if err != nil {
+					println("Scheduler error:", err.Error())
+				} else if count > 0 {
+					println("Scheduler: published", count, "scheduled items")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(PUBLISH_SCHEDULER_INTERVAL)
+		}
+	}()
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	TERM_SCAN_INTERVAL = time.Minute
+	TERM_SCAN_BATCH    = 500
+)
+
+var errAlreadyHandled = errors.New("item already handled")
+
+// checkStillPending restituisce errAlreadyHandled se nel frattempo un
+// moderatore ha deciso l'elemento o ne ha programmato la pubblicazione:
+// i rifiuti automatici non lo toccano più
+func checkStillPending(tx pgx.Tx, kind *ContentKind, id int) error {
+	status, err := QueryContentStatus(tx, kind, id)
+	if err != nil {
+		return err
+	}
+	if status != "received" {
+		return errAlreadyHandled
+	}
+	publishAt, err := QueryContentSchedule(tx, kind, id)
+	if err != nil {
+		return err
+	}
+	if publishAt != nil {
+		return errAlreadyHandled
+	}
+	return nil
+}
+
+var validTermSeverities = map[string]bool{"low": true, "medium": true, "high": true}
+var validTermActions = map[string]bool{"flag": true, "auto_reject": true}
+
+// Sostituzioni leetspeak più comuni
+var leetRunes = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '€': 'e',
+}
+
+// Lettere accentate latine ricondotte alla lettera base
+var accentRunes = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n', 'ý': 'y', 'ÿ': 'y',
+}
+
+// foldRune riduce un carattere alla forma confrontabile
+func foldRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if base, ok := accentRunes[r]; ok {
+		return base
+	}
+	if base, ok := leetRunes[r]; ok {
+		return base
+	}
+	return r
+}
+
+// isInvisible riconosce caratteri di formato (zero-width, soft hyphen, ...)
+// e segni combinanti, che vengono ignorati del tutto
+func isInvisible(r rune) bool {
+	return unicode.Is(unicode.Cf, r) || unicode.Is(unicode.Mn, r)
+}
+
+// normNode è una lettera del testo normalizzato. Le ripetizioni consecutive
+// della stessa lettera sono fuse in un solo nodo; start/end sono gli indici
+// (in rune) del tratto di testo originale coperto.
+type normNode struct {
+	r          rune
+	start, end int
+	wordStart  bool
+	wordEnd    bool
+}
+
+// normalizeText produce la sequenza di lettere confrontabili del testo:
+// minuscole, senza accenti né caratteri invisibili, leetspeak tradotto,
+// lettere ripetute fuse. La punteggiatura e gli spazi separano le parole
+// ma non compaiono nella sequenza, così "s t r o n z o" resta riconoscibile.
+func normalizeText(text string) []normNode {
+	var nodes []normNode
+	separated := true
+	for i, r := range []rune(text) {
+		if isInvisible(r) {
+			continue
+		}
+		m := foldRune(r)
+		if !unicode.IsLetter(m) && !unicode.IsDigit(m) {
+			if len(nodes) > 0 {
+				nodes[len(nodes)-1].wordEnd = true
+			}
+			separated = true
+			continue
+		}
+		if len(nodes) > 0 && !separated && nodes[len(nodes)-1].r == m {
+			nodes[len(nodes)-1].end = i + 1
+			continue
+		}
+		nodes = append(nodes, normNode{r: m, start: i, end: i + 1, wordStart: separated})
+		separated = false
+	}
+	if len(nodes) > 0 {
+		nodes[len(nodes)-1].wordEnd = true
+	}
+	return nodes
+}
+
+// normalizeTerm riduce un termine vietato alla stessa forma del testo
+func normalizeTerm(term string) []rune {
+	nodes := normalizeText(term)
+	runes := make([]rune, 0, len(nodes))
+	for _, n := range nodes {
+		if len(runes) > 0 && runes[len(runes)-1] == n.r {
+			continue
+		}
+		runes = append(runes, n.r)
+	}
+	return runes
+}
+
+type compiledTerm struct {
+	BannedTerm
+	runes []rune
+	re    *regexp.Regexp
+}
+
+// termMatcher applica un insieme di termini vietati a un testo
+type termMatcher struct {
+	terms []compiledTerm
+}
+
+// newTermMatcher prepara i termini; le regex non valide vengono scartate
+// (sono già validate in inserimento).
+func newTermMatcher(terms []BannedTerm) *termMatcher {
+	m := &termMatcher{}
+	for _, t := range terms {
+		c := compiledTerm{BannedTerm: t}
+		if t.IsRegex {
+			re, err := regexp.Compile(t.Pattern)
+			if err != nil {
+				println("Invalid banned term regex", t.ID, ":", err.Error())
+				continue
+			}
+			c.re = re
+		} else {
+			c.runes = normalizeTerm(t.Pattern)
+			if len(c.runes) == 0 {
+				continue
+			}
+		}
+		m.terms = append(m.terms, c)
+	}
+	return m
+}
+
+// matchAt prova a riconoscere term a partire dal nodo i: ogni lettera del
+// termine consuma uno o più nodi uguali (ripetizioni separate da spazi).
+// Restituisce l'indice dell'ultimo nodo consumato o -1.
+func matchAt(nodes []normNode, i int, term []rune) int {
+	k := i
+	for _, r := range term {
+		if k >= len(nodes) || nodes[k].r != r {
+			return -1
+		}
+		for k+1 < len(nodes) && nodes[k+1].r == r {
+			k++
+		}
+		k++
+	}
+	return k - 1
+}
+
+// spacedText ricostruisce il testo normalizzato con uno spazio tra le parole
+// (per le regex) e, per ogni rune, l'indice del nodo da cui proviene (-1 per gli spazi).
+func spacedText(nodes []normNode) (string, []int) {
+	var b strings.Builder
+	var owner []int
+	for k, n := range nodes {
+		if k > 0 && n.wordStart {
+			b.WriteRune(' ')
+			owner = append(owner, -1)
+		}
+		b.WriteRune(n.r)
+		owner = append(owner, k)
+	}
+	return b.String(), owner
+}
+
+// Match restituisce le occorrenze dei termini nel testo, con le posizioni
+// (in caratteri Unicode) nel testo originale.
+func (m *termMatcher) Match(text string) []TermMatch {
+	if m == nil || len(m.terms) == 0 {
+		return nil
+	}
+	original := []rune(text)
+	nodes := normalizeText(text)
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var spaced string
+	var owner []int
+
+	var matches []TermMatch
+	add := func(t compiledTerm, first, last int) {
+		start, end := nodes[first].start, nodes[last].end
+		matches = append(matches, TermMatch{
+			TermID:          t.ID,
+			Pattern:         t.Pattern,
+			Severity:        t.Severity,
+			Action:          t.Action,
+			RejectionReason: t.RejectionReason,
+			Start:           start,
+			End:             end,
+			Text:            string(original[start:end]),
+		})
+	}
+
+	for _, t := range m.terms {
+		if t.re != nil {
+			if owner == nil {
+				spaced, owner = spacedText(nodes)
+			}
+			for _, loc := range t.re.FindAllStringIndex(spaced, -1) {
+				// Da offset in byte a indici in rune, saltando gli spazi ai bordi
+				from := len([]rune(spaced[:loc[0]]))
+				to := len([]rune(spaced[:loc[1]])) - 1
+				for from <= to && owner[from] < 0 {
+					from++
+				}
+				for to >= from && owner[to] < 0 {
+					to--
+				}
+				if from > to {
+					continue
+				}
+				add(t, owner[from], owner[to])
+			}
+			continue
+		}
+
+		for i := 0; i < len(nodes); i++ {
+			if !nodes[i].wordStart {
+				continue
+			}
+			last := matchAt(nodes, i, t.runes)
+			if last >= 0 && nodes[last].wordEnd {
+				add(t, i, last)
+				i = last
+			}
+		}
+	}
+	return matches
+}
+
+// annotateTermMatches evidenzia i termini vietati negli elementi di una lista;
+// se i termini non sono leggibili la lista resta comunque utilizzabile.
+func annotateTermMatches(db DBTX, items []ContentItem) {
+	matcher, err := loadTermMatcher(db)
+	if err != nil {
+		println("Banned terms error:", err.Error())
+	}
+	for i := range items {
+		items[i].TermMatches = matcher.Match(items[i].Content)
+	}
+}
+
+// firstAutoReject restituisce il primo termine con azione auto_reject trovato
+func firstAutoReject(matches []TermMatch) *TermMatch {
+	for i := range matches {
+		if matches[i].Action == "auto_reject" {
+			return &matches[i]
+		}
+	}
+	return nil
+}
+
+// loadTermMatcher legge i termini attivi dal database
+func loadTermMatcher(db DBTX) (*termMatcher, error) {
+	terms, err := QueryActiveBannedTerms(db)
+	if err != nil {
+		return nil, err
+	}
+	return newTermMatcher(terms), nil
+}
+
+// scanPendingContent rifiuta automaticamente gli elementi in attesa che
+// contengono termini con azione auto_reject, con una voce di audit di sistema.
+// Gli elementi senza corrispondenze vengono segnati come controllati.
+func scanPendingContent(db *pgx.Conn) (int, error) {
+	matcher, err := loadTermMatcher(db)
+	if err != nil {
+		return 0, err
+	}
+	if firstAutoRejectTerm(matcher) == nil {
+		return 0, nil
+	}
+
+	rejected := 0
+	for _, name := range contentKindNames() {
+		kind := CONTENT_KINDS[name]
+		pending, err := QueryTermsUnchecked(db, kind, TERM_SCAN_BATCH)
+		if err != nil {
+			return rejected, err
+		}
+		for _, p := range pending {
+			match := firstAutoReject(matcher.Match(p.Content))
+			if match == nil {
+				if err := MarkTermsChecked(db, kind, p.ID); err != nil && !errors.Is(err, ErrNotFound) {
+					println("Banned terms error ("+kind.Name+"):", err.Error())
+				}
+				continue
+			}
+			entry := AuditEntry{
+				Action:     "auto_reject",
+				TargetType: kind.Name,
+				TargetID:   p.ID,
+				ActorRole:  ACTOR_SYSTEM,
+			}
+			reason := match.RejectionReason
+			note := "Termine vietato: " + match.Text
+			err := auditedStatusChange(db, entry, kind, p.ID, func(tx pgx.Tx) error {
+				if err := checkStillPending(tx, kind, p.ID); err != nil {
+					return err
+				}
+				return RejectContent(tx, kind, p.ID, reason, &note)
+			})
+			if errors.Is(err, errAlreadyHandled) {
+				continue
+			}
+			if err != nil {
+				println("Auto-reject error ("+kind.Name+"):", err.Error())
+				continue
+			}
+			rejected++
+		}
+	}
+	return rejected, nil
+}
+
+// firstAutoRejectTerm evita la scansione se nessun termine rifiuta automaticamente
+func firstAutoRejectTerm(m *termMatcher) *compiledTerm {
+	for i := range m.terms {
+		if m.terms[i].Action == "auto_reject" {
+			return &m.terms[i]
+		}
+	}
+	return nil
+}
+
+// startTermScanner avvia in background il controllo periodico della coda
+func startTermScanner() {
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("Term scan: cannot connect to database:", err.Error())
+			} else {
+				count, err := scanPendingContent(db)
+				if err != nil {
+					println("Term scan error:", err.Error())
+				} else if count > 0 {
+					println("Term scan: auto-rejected", count, "items")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(TERM_SCAN_INTERVAL)
+		}
+	}()
+}
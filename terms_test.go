@@ -0,0 +1,109 @@
+package main
+
+import "testing"
+
+// normalizedString ricompone le lettere normalizzate, separando le parole con uno spazio
+func normalizedString(text string) string {
+	s, _ := spacedText(normalizeText(text))
+	return s
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"minuscole", "CIAO Mondo", "ciao mondo"},
+		{"accenti", "perché così", "perche cosi"},
+		{"leet", "5tr0nz0 c14o", "stronzo ciao"},
+		{"simboli leet", "$c@mbi0", "scambio"},
+		{"zero-width", "str\u200bon\u200dzo", "stronzo"},
+		{"soft hyphen", "stron\u00adzo", "stronzo"},
+		{"segni combinanti", "cosi\u0300", "cosi"},
+		{"lettere ripetute", "ciaaaaoooo", "ciao"},
+		{"punteggiatura", "ciao, mondo!!", "ciao mondo"},
+		{"spazi", "  ciao \t\n mondo  ", "ciao mondo"},
+		{"solo simboli", "... !!! ???", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizedString(tt.text); got != tt.want {
+				t.Errorf("normalizeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTextOffsets(t *testing.T) {
+	// Le posizioni si riferiscono alle rune del testo originale
+	nodes := normalizeText("è  oOo")
+	if len(nodes) != 2 {
+		t.Fatalf("nodi %d, attesi 2", len(nodes))
+	}
+	if nodes[0].start != 0 || nodes[0].end != 1 || !nodes[0].wordStart || !nodes[0].wordEnd {
+		t.Errorf("primo nodo %+v", nodes[0])
+	}
+	if nodes[1].start != 3 || nodes[1].end != 6 || !nodes[1].wordStart || !nodes[1].wordEnd {
+		t.Errorf("secondo nodo %+v", nodes[1])
+	}
+}
+
+func TestTermMatcher(t *testing.T) {
+	matcher := newTermMatcher([]BannedTerm{
+		{ID: 1, Pattern: "stronzo", Action: "flag"},
+		{ID: 2, Pattern: "Cioè", Action: "auto_reject"},
+		{ID: 3, Pattern: `\bti\s+odio\b`, IsRegex: true, Action: "flag"},
+		{ID: 4, Pattern: "[invalid", IsRegex: true},
+		{ID: 5, Pattern: "!!!"},
+	})
+	if len(matcher.terms) != 3 {
+		t.Fatalf("termini compilati %d, attesi 3", len(matcher.terms))
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []TermMatch
+	}{
+		{"nessuna occorrenza", "ciao a tutti", nil},
+		{"esatto", "sei uno stronzo", []TermMatch{{TermID: 1, Start: 8, End: 15, Text: "stronzo"}}},
+		{"maiuscole", "STRONZO!", []TermMatch{{TermID: 1, Start: 0, End: 7, Text: "STRONZO"}}},
+		{"leet", "5tr0nz0", []TermMatch{{TermID: 1, Start: 0, End: 7, Text: "5tr0nz0"}}},
+		{"zero-width", "str\u200bonzo", []TermMatch{{TermID: 1, Start: 0, End: 8, Text: "str\u200bonzo"}}},
+		{"lettere ripetute", "strooonzooo", []TermMatch{{TermID: 1, Start: 0, End: 11, Text: "strooonzooo"}}},
+		{"lettere spaziate", "s t r o n z o", []TermMatch{{TermID: 1, Start: 0, End: 13, Text: "s t r o n z o"}}},
+		{"punti tra le lettere", "s.t.r.o.n.z.o", []TermMatch{{TermID: 1, Start: 0, End: 13, Text: "s.t.r.o.n.z.o"}}},
+		{"dentro un'altra parola", "stronzone", nil},
+		{"accento nel termine", "cioe", []TermMatch{{TermID: 2, Start: 0, End: 4, Text: "cioe"}}},
+		{"regex sul testo normalizzato", "io T1,  0DIO", []TermMatch{{TermID: 3, Start: 3, End: 12, Text: "T1,  0DIO"}}},
+		{"più occorrenze", "stronzo e 5TRONZO", []TermMatch{
+			{TermID: 1, Start: 0, End: 7, Text: "stronzo"},
+			{TermID: 1, Start: 10, End: 17, Text: "5TRONZO"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matcher.Match(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Match(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.TermID != w.TermID || g.Start != w.Start || g.End != w.End || g.Text != w.Text {
+					t.Errorf("occorrenza %d = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestFirstAutoReject(t *testing.T) {
+	matches := []TermMatch{{TermID: 1, Action: "flag"}, {TermID: 2, Action: "auto_reject"}, {TermID: 3, Action: "auto_reject"}}
+	if got := firstAutoReject(matches); got == nil || got.TermID != 2 {
+		t.Errorf("firstAutoReject = %+v, want term 2", got)
+	}
+	if got := firstAutoReject(matches[:1]); got != nil {
+		t.Errorf("firstAutoReject = %+v, want nil", got)
+	}
+}
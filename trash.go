@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DEFAULT_TRASH_RETENTION_DAYS = 30
+	TRASH_PURGE_INTERVAL         = time.Hour
+)
+
+// trashRetention restituisce per quanto tempo un elemento resta nel cestino
+func trashRetention() time.Duration {
+	days := CONF.TRASH_RETENTION_DAYS
+	if days <= 0 {
+		days = DEFAULT_TRASH_RETENTION_DAYS
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// purgeExpiredTrash elimina definitivamente gli elementi scaduti registrando
+// una voce di audit di sistema per ciascuno, nella stessa transazione.
+func purgeExpiredTrash(db *pgx.Conn) (int, error) {
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(context.Background())
+
+	purged, err := PurgeTrash(tx, trashRetention())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range purged {
+		entry := AuditEntry{
+			Action:     "purge",
+			TargetType: item.Kind,
+			TargetID:   item.ItemID,
+			ActorRole:  ACTOR_SYSTEM,
+			Before:     map[string]any{"content": item.Content, "deleted_at": item.DeletedAt},
+		}
+		if err := InsertAuditEntry(tx, entry); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(purged), tx.Commit(context.Background())
+}
+
+// startTrashPurger avvia in background la pulizia periodica del cestino
+func startTrashPurger() {
+	go func() {
+		for {
+			db, err := makeDbaseConnection()
+			if err != nil {
+				println("Trash purge: cannot connect to database:", err.Error())
+			} else {
+				count, err := purgeExpiredTrash(db)
+				if err != nil {
+					println("Trash purge error:", err.Error())
+				} else if count > 0 {
+					println("Trash purge: removed", count, "items")
+				}
+				db.Close(context.Background())
+			}
+			time.Sleep(TRASH_PURGE_INTERVAL)
+		}
+	}()
+}